@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
 	"go-clean-template/internal/infrastructure/config"
 	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/profiling"
+	"go-clean-template/internal/infrastructure/sysinfo"
+	"go-clean-template/internal/infrastructure/tracing"
 	"go-clean-template/internal/presentation/http"
 	"go-clean-template/internal/presentation/swagger"
 )
@@ -30,6 +38,9 @@ func main() {
 	defer func() {
 		_ = log.Sync()
 	}()
+	logger.SetDefault(log)
+	watchLogLevels(cfg, log)
+	tuneRuntime(cfg, log)
 
 	log.Info("Application starting",
 		logger.String("environment", cfg.Server.Environment),
@@ -37,6 +48,24 @@ func main() {
 		logger.String("port", cfg.Server.Port),
 	)
 
+	shutdownTracing, err := tracing.Init(cfg.Tracing, cfg.Server.Environment, "1.0.0")
+	if err != nil {
+		log.Fatal("Failed to initialize tracing", logger.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("Failed to flush traces", logger.Error(err))
+		}
+	}()
+
+	stopProfiling, err := profiling.StartContinuous(cfg.Profiling.Continuous, log.Named("profiling"))
+	if err != nil {
+		log.Fatal("Failed to start continuous profiling", logger.Error(err))
+	}
+	defer stopProfiling()
+
 	swagger.Initialize(cfg.Swagger)
 
 	server := http.NewServer(cfg, log)
@@ -44,3 +73,58 @@ func main() {
 		log.Fatal("Server failed to start", logger.Error(err))
 	}
 }
+
+// tuneRuntime sizes GOMAXPROCS and GOMEMLIMIT to the container limits
+func tuneRuntime(cfg *config.Config, log logger.Logger) {
+	tuning, err := sysinfo.ApplyRuntimeLimits(cfg.Server.Runtime)
+	if err != nil {
+		log.Fatal("Invalid runtime configuration", logger.Error(err))
+	}
+
+	log.Info("Runtime limits applied",
+		logger.Int("gomaxprocs", tuning.GOMAXPROCS),
+		logger.String("gomaxprocs_source", tuning.GOMAXPROCSSource),
+		logger.Int64("memory_limit", tuning.MemoryLimit),
+		logger.String("memory_limit_source", tuning.MemoryLimitSource),
+	)
+}
+
+// watchLogLevels lets operators change log levels at runtime through
+// SIGUSR1/SIGUSR2 and edits to the logging section of the config file
+func watchLogLevels(cfg *config.Config, log logger.Logger) {
+	levels := logger.Levels(log)
+	if levels == nil {
+		return
+	}
+
+	levelControl := cfg.Logging.LevelControl
+	go logger.HandleLevelSignals(context.Background(), levels, levelControl.SignalLevel,
+		time.Duration(levelControl.SignalTTL)*time.Second, log)
+
+	// Edits elsewhere in the file must not wipe temporary overrides, so
+	// levels are only applied when the logging section changed them
+	var mu sync.Mutex
+	appliedLevel, appliedModules := cfg.Logging.Level, cfg.Logging.Modules
+
+	config.Watch(func(reloaded *config.Config, err error) {
+		if err != nil {
+			log.Error("Failed to reload configuration", logger.Error(err))
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if reloaded.Logging.Level == appliedLevel && maps.Equal(reloaded.Logging.Modules, appliedModules) {
+			return
+		}
+		if err := levels.Configure(reloaded.Logging.Level, reloaded.Logging.Modules); err != nil {
+			log.Error("Ignoring invalid log levels from reloaded configuration", logger.Error(err))
+			return
+		}
+		appliedLevel, appliedModules = reloaded.Logging.Level, reloaded.Logging.Modules
+		log.Warn("Log levels reloaded from configuration",
+			logger.String("level", reloaded.Logging.Level),
+			logger.Any("modules", reloaded.Logging.Modules),
+		)
+	})
+}
@@ -0,0 +1,51 @@
+// Command audit inspects the hash-chained audit log written by the API.
+//
+//	go run ./cmd/audit verify -file ./logs/audit.log
+//
+// verify exits with status 1 when the chain is broken and reports the first
+// line that fails. An incomplete last line, left by an interrupted write, is
+// reported but does not fail verification; the API removes it on startup.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"go-clean-template/internal/infrastructure/audit"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "verify" {
+		fmt.Fprintln(os.Stderr, "usage: audit verify -file <path>")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	path := flags.String("file", "./logs/audit.log", "audit log to verify")
+	_ = flags.Parse(os.Args[2:])
+
+	file, err := os.Open(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	result, err := audit.Verify(file)
+	file.Close()
+	if err != nil {
+		var chainErr *audit.ChainError
+		if errors.As(err, &chainErr) {
+			fmt.Printf("BROKEN after %d valid records: %v\n", result.Records, chainErr)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("OK: %d records, last seq %d, head %s\n", result.Records, result.LastSeq, result.LastHash)
+	if result.TornBytes > 0 {
+		fmt.Printf("WARNING: incomplete trailing line of %d bytes after the last record\n", result.TornBytes)
+	}
+}
@@ -0,0 +1,158 @@
+// Command logbench compares request latency with synchronous and
+// asynchronous file logging. Requests go through RequestLogger into a
+// rotating file whose writes stall periodically to mimic a slow disk.
+//
+//	go run ./cmd/logbench -requests 20000 -concurrency 32 -stall 20ms -stall-every 500
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/presentation/http/middlewares"
+	"go-clean-template/internal/shared/response"
+)
+
+func main() {
+	requests := flag.Int("requests", 20000, "requests per run")
+	concurrency := flag.Int("concurrency", 32, "concurrent clients")
+	stall := flag.Duration("stall", 20*time.Millisecond, "simulated disk stall")
+	stallEvery := flag.Int("stall-every", 500, "stall once every N log entries; 0 disables stalls")
+	bufferSize := flag.Int("buffer", 8192, "async buffer size in entries")
+	overflow := flag.String("overflow", "block", "async overflow policy: block, drop_oldest or drop_newest")
+	flag.Parse()
+
+	policy, err := logger.ParseOverflowPolicy(*overflow)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	dir, err := os.MkdirTemp("", "logbench")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Printf("%d requests, %d clients, %s stall every %d entries\n\n", *requests, *concurrency, *stall, *stallEvery)
+	fmt.Printf("%-6s %10s %10s %10s %10s %12s %8s\n", "mode", "p50", "p90", "p99", "max", "req/s", "dropped")
+
+	for _, async := range []bool{false, true} {
+		file := &lumberjack.Logger{Filename: filepath.Join(dir, fmt.Sprintf("bench-%t.log", async)), MaxSize: 100}
+		var writer zapcore.WriteSyncer = zapcore.AddSync(&stallingWriter{out: file, stall: *stall, every: int64(*stallEvery)})
+
+		mode := "sync"
+		var asyncWriter *logger.AsyncWriter
+		if async {
+			mode = "async"
+			asyncWriter = logger.NewAsyncWriter(file.Filename, writer, logger.AsyncConfig{
+				BufferSize: *bufferSize,
+				Overflow:   policy,
+			})
+			writer = asyncWriter
+		}
+
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), writer, zapcore.InfoLevel)
+		log := logger.NewFromZap(zap.New(core))
+
+		latencies, elapsed := run(newRouter(log), *requests, *concurrency)
+		_ = log.Sync()
+
+		var dropped uint64
+		if asyncWriter != nil {
+			dropped = logger.Metrics().AsyncFiles[file.Filename].Dropped
+			_ = asyncWriter.Close()
+		}
+		_ = file.Close()
+
+		fmt.Printf("%-6s %10s %10s %10s %10s %12.0f %8d\n", mode,
+			percentile(latencies, 0.50), percentile(latencies, 0.90), percentile(latencies, 0.99),
+			latencies[len(latencies)-1], float64(len(latencies))/elapsed.Seconds(), dropped)
+	}
+}
+
+func newRouter(log logger.Logger) http.Handler {
+	r := chi.NewRouter()
+	r.Use(middlewares.RequestLogger(config.HTTPLoggingConfig{}, log))
+	r.Get("/bench", func(w http.ResponseWriter, r *http.Request) {
+		response.Success(w, r, map[string]string{"status": "ok"})
+	})
+	return r
+}
+
+// run sends requests straight to the handler so the figures exclude network noise
+func run(handler http.Handler, requests, concurrency int) ([]time.Duration, time.Duration) {
+	latencies := make([]time.Duration, requests)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for c := 0; c < concurrency; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := next.Add(1) - 1
+				if i >= int64(requests) {
+					return
+				}
+				req := httptest.NewRequest(http.MethodGet, "/bench", nil)
+				began := time.Now()
+				handler.ServeHTTP(httptest.NewRecorder(), req)
+				latencies[i] = time.Since(began)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies, elapsed
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// stallingWriter blocks for every N entries written, like a disk under
+// contention. Entries are counted by line so batched writes stall as often
+// as individual ones, and the lock serialises writers as a file would.
+type stallingWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	stall   time.Duration
+	every   int64
+	entries int64
+}
+
+func (w *stallingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.every > 0 {
+		before := w.entries / w.every
+		w.entries += int64(bytes.Count(p, []byte{'\n'}))
+		if stalls := w.entries/w.every - before; stalls > 0 {
+			time.Sleep(time.Duration(stalls) * w.stall)
+		}
+	}
+	return w.out.Write(p)
+}
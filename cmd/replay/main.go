@@ -0,0 +1,172 @@
+// Command replay sends a recorded request to a running instance and diffs
+// the response against the recorded one. Recordings come from a directory
+// store file or, for the memory store, from the admin API.
+//
+//	go run ./cmd/replay -file ./logs/recordings/<file>.json -target http://localhost:8080
+//	go run ./cmd/replay -id <request id> -admin http://localhost:9091 -token $ADMIN_TOKEN
+//
+// Redacted header values are not sent; pass real ones with -H. The exit
+// status is 1 when the responses differ.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/recording"
+)
+
+// skippedHeaders are set by the client or would change the replayed response
+var skippedHeaders = map[string]bool{
+	"Host": true, "Content-Length": true, "Connection": true,
+	"Accept-Encoding": true, "X-Request-Id": true,
+}
+
+type headerFlags []string
+
+func (h *headerFlags) String() string     { return strings.Join(*h, ", ") }
+func (h *headerFlags) Set(v string) error { *h = append(*h, v); return nil }
+
+func main() {
+	file := flag.String("file", "", "recording file written by the directory store")
+	id := flag.String("id", "", "recording ID to fetch from the admin API")
+	admin := flag.String("admin", "http://localhost:9091", "admin listener of the instance holding the recording")
+	token := flag.String("token", os.Getenv("ADMIN_TOKEN"), "admin token for -id")
+	target := flag.String("target", "http://localhost:8080", "base URL to replay against")
+	compare := flag.String("headers", "Content-Type", "comma-separated response headers to compare")
+	ignore := flag.String("ignore", "error.request_id", "comma-separated JSON body paths to ignore")
+	timeout := flag.Duration("timeout", 30*time.Second, "request timeout")
+	var overrides headerFlags
+	flag.Var(&overrides, "H", `request header to set, e.g. -H "Authorization: Bearer x"; repeatable`)
+	flag.Parse()
+
+	client := &http.Client{Timeout: *timeout}
+
+	var (
+		exchange *recording.Exchange
+		err      error
+	)
+	switch {
+	case *file != "":
+		exchange, err = recording.ReadFile(*file)
+	case *id != "":
+		exchange, err = fetch(client, *admin, *id, *token)
+	default:
+		err = fmt.Errorf("either -file or -id is required")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if exchange.Request.BodyTruncated || exchange.Request.BodyOmitted != "" {
+		fmt.Fprintln(os.Stderr, "warning: the recorded request body is incomplete; the replay may not be faithful")
+	}
+
+	replayed, err := replay(client, *target, exchange, overrides)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	diffs := recording.DiffResponses(exchange.Response, replayed, splitList(*compare), splitList(*ignore))
+	fmt.Printf("%s %s: recorded %d, replayed %d\n",
+		exchange.Request.Method, exchange.Request.URI, exchange.Response.Status, replayed.Status)
+	if len(diffs) == 0 {
+		fmt.Println("responses match")
+		return
+	}
+	for _, diff := range diffs {
+		fmt.Println("  " + diff.String())
+	}
+	os.Exit(1)
+}
+
+func fetch(client *http.Client, admin, id, token string) (*recording.Exchange, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(admin, "/")+"/recordings/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("fetching recording failed with %s: %s", resp.Status, body)
+	}
+
+	var exchange recording.Exchange
+	if err := json.NewDecoder(resp.Body).Decode(&exchange); err != nil {
+		return nil, fmt.Errorf("invalid recording: %w", err)
+	}
+	return &exchange, nil
+}
+
+func replay(client *http.Client, target string, exchange *recording.Exchange, overrides []string) (recording.Response, error) {
+	u, err := url.Parse(strings.TrimRight(target, "/") + exchange.Request.URI)
+	if err != nil {
+		return recording.Response{}, err
+	}
+	req, err := http.NewRequest(exchange.Request.Method, u.String(), strings.NewReader(exchange.Request.Body))
+	if err != nil {
+		return recording.Response{}, err
+	}
+
+	for name, value := range exchange.Request.Headers {
+		if skippedHeaders[http.CanonicalHeaderKey(name)] || strings.Contains(value, logger.RedactedValue) {
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	for _, override := range overrides {
+		name, value, ok := strings.Cut(override, ":")
+		if !ok {
+			return recording.Response{}, fmt.Errorf("invalid header %q, expected \"Name: value\"", override)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	req.Header.Set("X-Replay-Of", exchange.ID)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return recording.Response{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return recording.Response{}, err
+	}
+	// Redacted like the recording so masked values compare equal
+	return recording.Response{
+		Status: resp.StatusCode,
+		Message: recording.Message{
+			Headers:   logger.RedactHeaders(resp.Header),
+			Body:      logger.RedactBody(resp.Header.Get("Content-Type"), body),
+			BodyBytes: int64(len(body)),
+		},
+	}, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -24,7 +24,7 @@ const docTemplate = `{
     "paths": {
         "/health": {
             "get": {
-                "description": "Returns the basic health status of the service",
+                "description": "Aggregates the startup, liveness and readiness probes. Returns 503 when any of them fails; pass verbose for every check result.",
                 "produces": [
                     "application/json"
                 ],
@@ -32,12 +32,26 @@ const docTemplate = `{
                     "Health"
                 ],
                 "summary": "Get health status",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include the result of every check",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/handlers.HealthResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
                     }
                 }
             }
@@ -65,7 +79,7 @@ const docTemplate = `{
         },
         "/live": {
             "get": {
-                "description": "Checks if the service is alive and responding",
+                "description": "Liveness probe. Returns 503 when the internal heartbeat watchdog detects a wedged process; dependencies are not checked.",
                 "produces": [
                     "application/json"
                 ],
@@ -73,19 +87,33 @@ const docTemplate = `{
                     "Health"
                 ],
                 "summary": "Get liveness status",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include the result of every check",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/handlers.HealthResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
                     }
                 }
             }
         },
         "/ready": {
             "get": {
-                "description": "Checks if the service is ready to serve requests by verifying dependencies",
+                "description": "Readiness probe. Returns 503 before startup completed, while draining for shutdown, or when a critical dependency check fails.",
                 "produces": [
                     "application/json"
                 ],
@@ -93,31 +121,59 @@ const docTemplate = `{
                     "Health"
                 ],
                 "summary": "Get readiness status",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include the result of every check",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/handlers.HealthResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
                     }
                 }
             }
         },
-        "/system": {
+        "/startup": {
             "get": {
-                "description": "Returns detailed system information including memory usage, CPU count, and runtime stats",
+                "description": "Startup probe. Returns 503 until initialization, such as binding the listener, has completed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Health"
                 ],
-                "summary": "Get system information",
+                "summary": "Get startup status",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include the result of every check",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.SystemInfoResponse"
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
                         }
                     }
                 }
@@ -134,6 +190,18 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "probes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Report"
+                    }
+                },
                 "service": {
                     "type": "string"
                 },
@@ -151,37 +219,36 @@ const docTemplate = `{
                 }
             }
         },
-        "handlers.SystemInfoResponse": {
+        "health.Report": {
             "type": "object",
             "properties": {
-                "go_version": {
-                    "type": "string"
-                },
-                "memory": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.Result"
                     }
                 },
-                "num_cpu": {
-                    "type": "integer"
-                },
-                "num_goroutine": {
-                    "type": "integer"
-                },
-                "service": {
+                "status": {
                     "type": "string"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean"
                 },
-                "status": {
+                "duration": {
                     "type": "string"
                 },
-                "timestamp": {
+                "error": {
                     "type": "string"
                 },
-                "uptime": {
+                "name": {
                     "type": "string"
                 },
-                "version": {
+                "status": {
                     "type": "string"
                 }
             }
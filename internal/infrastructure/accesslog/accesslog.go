@@ -0,0 +1,104 @@
+// Package accesslog writes one line per HTTP request in a classic access log
+// format to its own rotated file, separate from the application logger.
+package accesslog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+const megabyte = 1024 * 1024
+
+// Logger formats entries and appends them to a rotated file
+type Logger struct {
+	formatter Formatter
+
+	mu      sync.Mutex
+	file    *lumberjack.Logger
+	maxSize int64
+	size    int64 // Bytes in the current file
+	buf     bytes.Buffer
+}
+
+// New opens the access log described by cfg
+func New(cfg config.AccessLogConfig) (*Logger, error) {
+	formatter, err := NewFormatter(cfg.Format, cfg.Template, cfg.W3CFields)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.File == "" {
+		return nil, fmt.Errorf("access log file is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.File), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create access log directory: %w", err)
+	}
+
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	l := &Logger{
+		formatter: formatter,
+		file: &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    maxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+		},
+		maxSize: int64(maxSize) * megabyte,
+	}
+	if info, err := os.Stat(cfg.File); err == nil {
+		l.size = info.Size()
+	}
+	return l, nil
+}
+
+// Log appends one entry. Rotation is done here rather than left to
+// lumberjack so format headers can be written at the top of every file.
+func (l *Logger) Log(entry *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf.Reset()
+	l.formatter.Format(&l.buf, entry)
+	l.buf.WriteByte('\n')
+
+	if l.size > 0 && l.size+int64(l.buf.Len()) > l.maxSize {
+		if err := l.file.Rotate(); err != nil {
+			return fmt.Errorf("failed to rotate access log: %w", err)
+		}
+		l.size = 0
+	}
+	if l.size == 0 {
+		if header := l.formatter.Header(time.Now()); header != nil {
+			if err := l.write(header); err != nil {
+				return err
+			}
+		}
+	}
+	return l.write(l.buf.Bytes())
+}
+
+func (l *Logger) write(p []byte) error {
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write access log: %w", err)
+	}
+	return nil
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
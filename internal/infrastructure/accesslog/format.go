@@ -0,0 +1,319 @@
+package accesslog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-clean-template/internal/infrastructure/logger"
+)
+
+// Supported formats
+const (
+	FormatCommon   = "common"
+	FormatCombined = "combined"
+	FormatW3C      = "w3c"
+	FormatTemplate = "template"
+
+	clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+// DefaultW3CFields is used when format is w3c and no fields are configured
+var DefaultW3CFields = []string{
+	"date", "time", "c-ip", "cs-username", "cs-method", "cs-uri-stem", "cs-uri-query",
+	"sc-status", "sc-bytes", "time-taken", "cs(User-Agent)", "cs(Referer)",
+}
+
+// Entry is one completed request
+type Entry struct {
+	Time          time.Time // When the request started
+	RemoteAddr    string    // Client address without port
+	User          string
+	Method        string
+	URI           string // Path and query, query already redacted
+	Path          string
+	Query         string
+	Proto         string
+	Host          string
+	Status        int
+	BytesSent     int64
+	BytesReceived int64
+	Duration      time.Duration
+	RequestID     string
+	CorrelationID string
+	Header        http.Header // Request headers
+}
+
+// header returns a request header, masked when it is sensitive
+func (e *Entry) header(name string) string {
+	value := e.Header.Get(name)
+	if value == "" {
+		return ""
+	}
+	return logger.RedactHeader(name, value)
+}
+
+// Formatter renders entries as lines
+type Formatter interface {
+	// Header returns directives written at the top of each new file, if any
+	Header(now time.Time) []byte
+	Format(buf *bytes.Buffer, entry *Entry)
+}
+
+// NewFormatter returns the formatter for format. template and w3cFields are
+// only used by their formats.
+func NewFormatter(format, template string, w3cFields []string) (Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", FormatCombined:
+		return clfFormatter{combined: true}, nil
+	case FormatCommon:
+		return clfFormatter{}, nil
+	case FormatW3C:
+		return newW3CFormatter(w3cFields)
+	case FormatTemplate:
+		return newTemplateFormatter(template)
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", format)
+	}
+}
+
+// clfFormatter writes the Common Log Format, optionally extended with
+// referer and user agent as in Apache's combined format
+type clfFormatter struct {
+	combined bool
+}
+
+func (clfFormatter) Header(time.Time) []byte { return nil }
+
+func (f clfFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	buf.WriteString(dash(e.RemoteAddr))
+	buf.WriteString(" - ")
+	buf.WriteString(dash(e.User))
+	buf.WriteString(" [")
+	buf.WriteString(e.Time.Format(clfTimeLayout))
+	buf.WriteString(`] "`)
+	buf.WriteString(escapeQuoted(e.Method + " " + e.URI + " " + e.Proto))
+	buf.WriteString(`" `)
+	buf.WriteString(strconv.Itoa(e.Status))
+	buf.WriteByte(' ')
+	if e.BytesSent > 0 {
+		buf.WriteString(strconv.FormatInt(e.BytesSent, 10))
+	} else {
+		buf.WriteByte('-')
+	}
+	if f.combined {
+		buf.WriteString(` "`)
+		buf.WriteString(escapeQuoted(dash(e.header("Referer"))))
+		buf.WriteString(`" "`)
+		buf.WriteString(escapeQuoted(dash(e.header("User-Agent"))))
+		buf.WriteByte('"')
+	}
+}
+
+// w3cFormatter writes the W3C extended log file format with a #Fields
+// directive describing the columns
+type w3cFormatter struct {
+	fields []string
+	values []func(e *Entry) string
+}
+
+func newW3CFormatter(fields []string) (*w3cFormatter, error) {
+	if len(fields) == 0 {
+		fields = DefaultW3CFields
+	}
+	f := &w3cFormatter{fields: fields}
+	for _, field := range fields {
+		value, err := w3cField(field)
+		if err != nil {
+			return nil, err
+		}
+		f.values = append(f.values, value)
+	}
+	return f, nil
+}
+
+func w3cField(field string) (func(e *Entry) string, error) {
+	switch field {
+	case "date":
+		return func(e *Entry) string { return e.Time.UTC().Format("2006-01-02") }, nil
+	case "time":
+		return func(e *Entry) string { return e.Time.UTC().Format("15:04:05") }, nil
+	case "c-ip":
+		return func(e *Entry) string { return e.RemoteAddr }, nil
+	case "cs-username":
+		return func(e *Entry) string { return e.User }, nil
+	case "cs-method":
+		return func(e *Entry) string { return e.Method }, nil
+	case "cs-uri":
+		return func(e *Entry) string { return e.URI }, nil
+	case "cs-uri-stem":
+		return func(e *Entry) string { return e.Path }, nil
+	case "cs-uri-query":
+		return func(e *Entry) string { return e.Query }, nil
+	case "cs-version":
+		return func(e *Entry) string { return e.Proto }, nil
+	case "cs-host":
+		return func(e *Entry) string { return e.Host }, nil
+	case "sc-status":
+		return func(e *Entry) string { return strconv.Itoa(e.Status) }, nil
+	case "sc-bytes":
+		return func(e *Entry) string { return strconv.FormatInt(e.BytesSent, 10) }, nil
+	case "cs-bytes":
+		return func(e *Entry) string { return strconv.FormatInt(e.BytesReceived, 10) }, nil
+	case "time-taken":
+		return func(e *Entry) string { return strconv.FormatFloat(e.Duration.Seconds(), 'f', 3, 64) }, nil
+	}
+	if name, ok := strings.CutPrefix(field, "cs("); ok && strings.HasSuffix(name, ")") {
+		name = strings.TrimSuffix(name, ")")
+		return func(e *Entry) string { return e.header(name) }, nil
+	}
+	return nil, fmt.Errorf("unsupported w3c field %q", field)
+}
+
+func (f *w3cFormatter) Header(now time.Time) []byte {
+	return []byte(fmt.Sprintf("#Software: go-clean-template\n#Version: 1.0\n#Date: %s\n#Fields: %s\n",
+		now.UTC().Format("2006-01-02 15:04:05"), strings.Join(f.fields, " ")))
+}
+
+func (f *w3cFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	for i, value := range f.values {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(w3cValue(value(e)))
+	}
+}
+
+// w3cValue encodes a value so it stays a single space-separated column
+func w3cValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ':
+			return '+'
+		case '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// templateFormatter expands nginx style $variables
+type templateFormatter struct {
+	parts []func(buf *bytes.Buffer, e *Entry)
+}
+
+var templateVariables = map[string]func(e *Entry) string{
+	"remote_addr":     func(e *Entry) string { return dash(e.RemoteAddr) },
+	"remote_user":     func(e *Entry) string { return dash(e.User) },
+	"time_local":      func(e *Entry) string { return e.Time.Format(clfTimeLayout) },
+	"time_iso8601":    func(e *Entry) string { return e.Time.Format(time.RFC3339) },
+	"request":         func(e *Entry) string { return escapeQuoted(e.Method + " " + e.URI + " " + e.Proto) },
+	"request_method":  func(e *Entry) string { return e.Method },
+	"request_uri":     func(e *Entry) string { return escapeQuoted(e.URI) },
+	"uri":             func(e *Entry) string { return escapeQuoted(e.Path) },
+	"args":            func(e *Entry) string { return escapeQuoted(e.Query) },
+	"server_protocol": func(e *Entry) string { return e.Proto },
+	"status":          func(e *Entry) string { return strconv.Itoa(e.Status) },
+	"body_bytes_sent": func(e *Entry) string { return strconv.FormatInt(e.BytesSent, 10) },
+	"request_length":  func(e *Entry) string { return strconv.FormatInt(e.BytesReceived, 10) },
+	"request_time":    func(e *Entry) string { return strconv.FormatFloat(e.Duration.Seconds(), 'f', 3, 64) },
+	"host":            func(e *Entry) string { return escapeQuoted(e.Host) },
+	"request_id":      func(e *Entry) string { return dash(e.RequestID) },
+	"correlation_id":  func(e *Entry) string { return dash(e.CorrelationID) },
+}
+
+func newTemplateFormatter(template string) (*templateFormatter, error) {
+	if template == "" {
+		return nil, fmt.Errorf("access log template is empty")
+	}
+
+	f := &templateFormatter{}
+	for len(template) > 0 {
+		i := strings.IndexByte(template, '$')
+		if i < 0 {
+			f.literal(template)
+			break
+		}
+		if i > 0 {
+			f.literal(template[:i])
+		}
+		template = template[i+1:]
+
+		end := 0
+		for end < len(template) && isVariableChar(template[end]) {
+			end++
+		}
+		name := template[:end]
+		template = template[end:]
+		if name == "" {
+			f.literal("$")
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(name, "http_"); ok {
+			header = strings.ReplaceAll(header, "_", "-")
+			f.parts = append(f.parts, func(buf *bytes.Buffer, e *Entry) {
+				buf.WriteString(escapeQuoted(dash(e.header(header))))
+			})
+			continue
+		}
+		value, ok := templateVariables[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown access log template variable $%s", name)
+		}
+		f.parts = append(f.parts, func(buf *bytes.Buffer, e *Entry) {
+			buf.WriteString(value(e))
+		})
+	}
+	return f, nil
+}
+
+func (f *templateFormatter) literal(text string) {
+	f.parts = append(f.parts, func(buf *bytes.Buffer, _ *Entry) {
+		buf.WriteString(text)
+	})
+}
+
+func (*templateFormatter) Header(time.Time) []byte { return nil }
+
+func (f *templateFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	for _, part := range f.parts {
+		part(buf, e)
+	}
+}
+
+func isVariableChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// escapeQuoted escapes quotes, backslashes and control characters the way
+// Apache does, so a value cannot break out of its quoted column or line
+func escapeQuoted(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
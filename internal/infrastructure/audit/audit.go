@@ -0,0 +1,308 @@
+// Package audit records security-relevant actions in an append-only file.
+// Every record carries the hash of the previous one, so edits, deletions and
+// reordering break the chain and are caught by Verify.
+//
+// The chain cannot tell when records were cut from the end of the file, since
+// what remains is still a valid chain. Compare the head (last seq and hash)
+// with one kept elsewhere, such as the head the API logs when it opens the
+// file, to detect that.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outcome is the result of an audited action
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomeDenied  Outcome = "denied"
+)
+
+// Common actions emitted by the HTTP layer
+const (
+	ActionAuthFailure    = "auth.failure"
+	ActionRateLimitBlock = "rate_limit.block"
+	ActionAdminCall      = "admin.call"
+)
+
+// Event describes who did what to which resource, and how it ended
+type Event struct {
+	Actor         string            `json:"actor"`
+	Action        string            `json:"action"`
+	Resource      string            `json:"resource"`
+	Outcome       Outcome           `json:"outcome"`
+	IP            string            `json:"ip,omitempty"`
+	RequestID     string            `json:"request_id,omitempty"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	Details       map[string]string `json:"details,omitempty"`
+}
+
+// Record is an Event as stored, with its position and hash chain
+type Record struct {
+	Seq      uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	Event              // Embedded so the stored line stays flat
+	PrevHash string    `json:"prev_hash"`
+	Hash     string    `json:"hash"`
+}
+
+// Recorder stores audit events
+type Recorder interface {
+	Record(ctx context.Context, event Event) error
+	Close() error
+}
+
+// GenesisHash is the previous hash of the first record in a file
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// ComputeHash hashes a record's content together with the previous hash.
+// The Hash field itself is excluded.
+func ComputeHash(record Record) (string, error) {
+	record.Hash = ""
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FileRecorder appends records to a file, syncing each one to disk
+type FileRecorder struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      uint64
+	lastHash string
+	// size is the length of the file up to the last complete record; a
+	// failed append is truncated back to it
+	size   int64
+	opened VerifyResult
+	// err is set when a failed append could not be rolled back, after which
+	// the file is no longer appended to
+	err error
+	now func() time.Time
+}
+
+// NewFileRecorder opens or creates path for appending and resumes the chain
+// from its last record. It refuses to append to a file whose chain is broken,
+// but an incomplete trailing line, left by a crash mid-write, is removed and
+// reported through Opened.
+func NewFileRecorder(path string) (*FileRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	existing, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer existing.Close()
+	case errors.Is(err, os.ErrNotExist):
+		existing = nil
+	default:
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	recorder := &FileRecorder{lastHash: GenesisHash, now: time.Now}
+	if existing != nil {
+		result, err := Verify(existing)
+		if err != nil {
+			return nil, fmt.Errorf("audit log %s failed verification: %w", path, err)
+		}
+		recorder.seq, recorder.lastHash, recorder.size = result.LastSeq, result.LastHash, result.Size
+		recorder.opened = result
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log for appending: %w", err)
+	}
+	if recorder.opened.TornBytes > 0 {
+		if err := file.Truncate(recorder.size); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to remove torn audit record: %w", err)
+		}
+	}
+	recorder.file = file
+	return recorder, nil
+}
+
+// Opened reports the chain found when the file was opened, including the
+// size of a torn trailing line that was removed
+func (f *FileRecorder) Opened() VerifyResult {
+	return f.opened
+}
+
+// Head returns the sequence number and hash of the last record written
+func (f *FileRecorder) Head() (uint64, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.seq, f.lastHash
+}
+
+// Record appends event as the next link of the chain
+func (f *FileRecorder) Record(_ context.Context, event Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+
+	record := Record{
+		Seq:      f.seq + 1,
+		Time:     f.now().UTC(),
+		Event:    event,
+		PrevHash: f.lastHash,
+	}
+	hash, err := ComputeHash(record)
+	if err != nil {
+		return fmt.Errorf("failed to hash audit record: %w", err)
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := f.file.Write(line); err != nil {
+		return f.rollback(fmt.Errorf("failed to write audit record: %w", err))
+	}
+	if err := f.file.Sync(); err != nil {
+		return f.rollback(fmt.Errorf("failed to sync audit log: %w", err))
+	}
+
+	f.seq, f.lastHash = record.Seq, record.Hash
+	f.size += int64(len(line))
+	return nil
+}
+
+// rollback truncates a failed append so the next record does not land on a
+// torn line or reuse the sequence number of one that made it to disk
+func (f *FileRecorder) rollback(cause error) error {
+	if err := f.file.Truncate(f.size); err != nil {
+		f.err = fmt.Errorf("audit log left inconsistent after failed append: %w", errors.Join(cause, err))
+		return f.err
+	}
+	return cause
+}
+
+func (f *FileRecorder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// Nop discards events; it is used when auditing is disabled
+type Nop struct{}
+
+func (Nop) Record(context.Context, Event) error { return nil }
+func (Nop) Close() error                        { return nil }
+
+// VerifyResult summarises a verified chain
+type VerifyResult struct {
+	Records  uint64
+	LastSeq  uint64
+	LastHash string
+	// Size is the length in bytes of the verified records
+	Size int64
+	// TornBytes is the length of an incomplete final line. It is the mark of
+	// an append interrupted by a crash rather than of tampering, so it is
+	// reported here and not as a ChainError.
+	TornBytes int64
+}
+
+// ChainError reports where and why a chain stopped verifying
+type ChainError struct {
+	Line   int
+	Seq    uint64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// Verify reads records from r and checks sequence numbers, links and hashes
+func Verify(r io.Reader) (VerifyResult, error) {
+	result := VerifyResult{LastHash: GenesisHash}
+
+	reader := bufio.NewReader(r)
+	line := 0
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return result, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		line++
+
+		// Every append ends with a newline, so a final line without one
+		// was never completed
+		if chunk[len(chunk)-1] != '\n' {
+			result.TornBytes = int64(len(chunk))
+			break
+		}
+
+		record, chainErr := verifyRecord(chunk[:len(chunk)-1], line, result)
+		if chainErr != nil {
+			return result, chainErr
+		}
+		result.Records++
+		result.LastSeq, result.LastHash = record.Seq, record.Hash
+		result.Size += int64(len(chunk))
+	}
+	return result, nil
+}
+
+// verifyRecord checks one line against the chain verified so far. The hash
+// covers the decoded record, so the line must also be exactly the encoding
+// Record writes: unknown keys, reordered keys or changed whitespace would
+// otherwise be lost in decoding and go unnoticed.
+func verifyRecord(raw []byte, line int, result VerifyResult) (Record, *ChainError) {
+	var record Record
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&record); err != nil {
+		return record, &ChainError{Line: line, Reason: "malformed record: " + err.Error()}
+	}
+	canonical, err := json.Marshal(record)
+	if err != nil {
+		return record, &ChainError{Line: line, Seq: record.Seq, Reason: err.Error()}
+	}
+	if !bytes.Equal(canonical, raw) {
+		return record, &ChainError{Line: line, Seq: record.Seq, Reason: "record is not in its written form"}
+	}
+	if record.Seq != result.LastSeq+1 {
+		return record, &ChainError{Line: line, Seq: record.Seq,
+			Reason: fmt.Sprintf("expected seq %d", result.LastSeq+1)}
+	}
+	if record.PrevHash != result.LastHash {
+		return record, &ChainError{Line: line, Seq: record.Seq, Reason: "previous hash does not match"}
+	}
+	hash, err := ComputeHash(record)
+	if err != nil {
+		return record, &ChainError{Line: line, Seq: record.Seq, Reason: err.Error()}
+	}
+	if hash != record.Hash {
+		return record, &ChainError{Line: line, Seq: record.Seq, Reason: "hash does not match content"}
+	}
+	return record, nil
+}
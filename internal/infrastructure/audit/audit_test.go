@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeChain records n events into a fresh file and returns its path
+func writeChain(t *testing.T, n int) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	recorder, err := NewFileRecorder(path)
+	if err != nil {
+		t.Fatalf("NewFileRecorder: %v", err)
+	}
+	recorder.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	for i := 0; i < n; i++ {
+		err := recorder.Record(context.Background(), Event{
+			Actor:    "admin-token",
+			Action:   ActionAdminCall,
+			Resource: "GET /system",
+			Outcome:  OutcomeSuccess,
+			IP:       "127.0.0.1",
+			Details:  map[string]string{"status": "200"},
+		})
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return path
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return strings.SplitAfter(string(content), "\n")
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(lines []string) []string
+		wantLine int
+		wantTorn bool
+	}{
+		{
+			name:   "intact chain",
+			tamper: func(lines []string) []string { return lines },
+		},
+		{
+			name: "changed value",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"actor":"admin-token"`, `"actor":"someone"`, 1)
+				return lines
+			},
+			wantLine: 2,
+		},
+		{
+			name: "added field",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `{"seq":2,`, `{"seq":2,"note":"x",`, 1)
+				return lines
+			},
+			wantLine: 2,
+		},
+		{
+			name: "reordered keys",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"actor":"admin-token","action":"admin.call"`,
+					`"action":"admin.call","actor":"admin-token"`, 1)
+				return lines
+			},
+			wantLine: 2,
+		},
+		{
+			name: "reformatted line",
+			tamper: func(lines []string) []string {
+				lines[0] = strings.Replace(lines[0], `,"actor"`, `, "actor"`, 1)
+				return lines
+			},
+			wantLine: 1,
+		},
+		{
+			name: "deleted record",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			wantLine: 2,
+		},
+		{
+			name: "swapped records",
+			tamper: func(lines []string) []string {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+			wantLine: 1,
+		},
+		{
+			name: "torn trailing line",
+			tamper: func(lines []string) []string {
+				return append(lines, `{"seq":4,"time":"2026-01`)
+			},
+			wantTorn: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeChain(t, 3)
+			content := strings.Join(tt.tamper(readLines(t, path)), "")
+
+			result, err := Verify(strings.NewReader(content))
+
+			var chainErr *ChainError
+			switch {
+			case tt.wantLine == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantLine != 0 && !errors.As(err, &chainErr):
+				t.Fatalf("expected a ChainError, got %v", err)
+			case tt.wantLine != 0 && chainErr.Line != tt.wantLine:
+				t.Fatalf("chain broke at line %d, want %d: %v", chainErr.Line, tt.wantLine, chainErr)
+			}
+			if tt.wantLine == 0 && result.Records != 3 {
+				t.Errorf("verified %d records, want 3", result.Records)
+			}
+			if got := result.TornBytes > 0; got != tt.wantTorn {
+				t.Errorf("TornBytes = %d, want torn %t", result.TornBytes, tt.wantTorn)
+			}
+		})
+	}
+}
+
+func TestNewFileRecorderRemovesTornLine(t *testing.T) {
+	path := writeChain(t, 2)
+	intact, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	torn := `{"seq":3,"time":"2026-01`
+	if err := os.WriteFile(path, append(append([]byte(nil), intact...), torn...), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	recorder, err := NewFileRecorder(path)
+	if err != nil {
+		t.Fatalf("NewFileRecorder refused a torn trailing line: %v", err)
+	}
+	if opened := recorder.Opened(); opened.TornBytes != int64(len(torn)) || opened.LastSeq != 2 {
+		t.Fatalf("unexpected open result %+v", opened)
+	}
+	if err := recorder.Record(context.Background(), Event{Actor: "a", Action: ActionAuthFailure, Outcome: OutcomeDenied}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	_ = recorder.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.HasPrefix(content, intact) || bytes.Contains(content, []byte(torn)) {
+		t.Fatal("torn line was not removed before appending")
+	}
+	result, err := Verify(bytes.NewReader(content))
+	if err != nil || result.LastSeq != 3 || result.TornBytes != 0 {
+		t.Fatalf("chain after recovery: %+v, %v", result, err)
+	}
+}
+
+func TestNewFileRecorderRefusesBrokenChain(t *testing.T) {
+	path := writeChain(t, 2)
+	lines := readLines(t, path)
+	lines[0] = strings.Replace(lines[0], `"outcome":"success"`, `"outcome":"denied"`, 1)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := NewFileRecorder(path); err == nil {
+		t.Fatal("expected a broken chain to be refused")
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+// ErrNotFound is returned by Get when the key does not exist or has expired
+var ErrNotFound = errors.New("cache: key not found")
+
+// Store is a byte oriented key/value store with per-key expiry
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value only if key does not exist and reports whether it did
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Pinger is implemented by stores backed by a remote service, so readiness
+// can depend on it
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+const (
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// New creates a store for the given driver, prefixing every key with prefix
+func New(driver, prefix string, redisConfig config.RedisConfig) (Store, error) {
+	switch driver {
+	case "", DriverMemory:
+		return NewMemoryStore(), nil
+	case DriverRedis:
+		return NewRedisStore(NewRedisClient(redisConfig), prefix), nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", driver)
+	}
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// MemoryStore is a process local Store. Expired entries are dropped lazily
+// on access and periodically by a background sweep.
+type MemoryStore struct {
+	entries   map[string]memoryEntry
+	mu        sync.RWMutex
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewMemoryStore() *MemoryStore {
+	store := &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		stop:    make(chan struct{}),
+	}
+	go store.sweep(time.Minute)
+	return store
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	entry, ok := s.entries[key]
+	s.mu.RUnlock()
+
+	if !ok || entry.expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = newMemoryEntry(value, ttl)
+	return nil
+}
+
+func (s *MemoryStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.expired(time.Now()) {
+		return false, nil
+	}
+	s.entries[key] = newMemoryEntry(value, ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Close stops the background sweep
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
+
+func (s *MemoryStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, entry := range s.entries {
+				if entry.expired(now) {
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func newMemoryEntry(value []byte, ttl time.Duration) memoryEntry {
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	return entry
+}
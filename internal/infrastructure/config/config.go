@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
@@ -21,14 +22,43 @@ type Config struct {
 	CORS      CORSConfig      `mapstructure:"cors"`
 	Metrics   MetricsConfig   `mapstructure:"metrics"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	HTTPCache   HTTPCacheConfig   `mapstructure:"http_cache"`
+	Compression CompressionConfig `mapstructure:"compression"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+	Audit       AuditConfig       `mapstructure:"audit"`
+	AccessLog   AccessLogConfig   `mapstructure:"access_log"`
+	Recorder    RecorderConfig    `mapstructure:"recorder"`
+	Profiling   ProfilingConfig   `mapstructure:"profiling"`
+	Health      HealthConfig      `mapstructure:"health"`
 }
 
 type ServerConfig struct {
-	Port         string `mapstructure:"port"`
-	Host         string `mapstructure:"host"`
-	Environment  string `mapstructure:"environment"`
-	ReadTimeout  int    `mapstructure:"read_timeout"`
-	WriteTimeout int    `mapstructure:"write_timeout"`
+	Port         string        `mapstructure:"port"`
+	Host         string        `mapstructure:"host"`
+	Environment  string        `mapstructure:"environment"`
+	ReadTimeout  int           `mapstructure:"read_timeout"`
+	WriteTimeout int           `mapstructure:"write_timeout"`
+	Runtime      RuntimeConfig `mapstructure:"runtime"`
+}
+
+// HealthConfig tunes the startup, liveness and readiness probes
+type HealthConfig struct {
+	CheckTimeout      int `mapstructure:"check_timeout"`      // Seconds per readiness dependency check
+	HeartbeatInterval int `mapstructure:"heartbeat_interval"` // Seconds between watchdog heartbeats
+	HeartbeatTimeout  int `mapstructure:"heartbeat_timeout"`  // Liveness fails after this many seconds without one
+	DrainDelay        int `mapstructure:"drain_delay"`        // Seconds readiness fails before shutdown begins
+}
+
+// RuntimeConfig sizes the Go runtime to the container at startup. Explicit
+// GOMAXPROCS and GOMEMLIMIT environment variables always win.
+type RuntimeConfig struct {
+	AutoMaxProcs  bool    `mapstructure:"auto_maxprocs"`  // GOMAXPROCS from the cgroup CPU quota
+	MinMaxProcs   int     `mapstructure:"min_maxprocs"`   // Floor for fractional quotas
+	AutoMemLimit  bool    `mapstructure:"auto_memlimit"`  // GOMEMLIMIT from the cgroup memory limit
+	MemLimitRatio float64 `mapstructure:"memlimit_ratio"` // Share of the limit given to the Go heap
 }
 
 type DatabaseConfig struct {
@@ -52,13 +82,94 @@ type AuthConfig struct {
 }
 
 type LoggingConfig struct {
-	Level            string            `mapstructure:"level"`
-	Format           string            `mapstructure:"format"`
-	StartupLevel     string            `mapstructure:"startup_level"`
-	StartupFormat    string            `mapstructure:"startup_format"`
-	EnableCaller     bool              `mapstructure:"enable_caller"`
-	EnableStacktrace bool              `mapstructure:"enable_stacktrace"`
-	File             FileLoggingConfig `mapstructure:"file"`
+	Level            string             `mapstructure:"level"`
+	Format           string             `mapstructure:"format"`
+	StartupLevel     string             `mapstructure:"startup_level"`
+	StartupFormat    string             `mapstructure:"startup_format"`
+	EnableCaller     bool               `mapstructure:"enable_caller"`
+	EnableStacktrace bool               `mapstructure:"enable_stacktrace"`
+	File             FileLoggingConfig  `mapstructure:"file"`
+	Modules          map[string]string  `mapstructure:"modules"` // Logger name -> level override
+	LevelControl     LevelControlConfig `mapstructure:"level_control"`
+	Sampling         SamplingConfig     `mapstructure:"sampling"`
+	Redaction        RedactionConfig    `mapstructure:"redaction"`
+	Sinks            []LogSinkConfig    `mapstructure:"sinks"`
+	HTTP             HTTPLoggingConfig  `mapstructure:"http"`
+}
+
+// HTTPLoggingConfig controls which requests RequestLogger logs and how much
+// detail it records
+type HTTPLoggingConfig struct {
+	SkipPaths    []string             `mapstructure:"skip_paths"`     // Exact paths
+	SkipGlobs    []string             `mapstructure:"skip_globs"`     // * within a segment, ** across segments
+	SampleRate   float64              `mapstructure:"sample_rate"`    // Share of successful requests logged; 0 logs all
+	SlowWarnMs   int                  `mapstructure:"slow_warn_ms"`   // 0 disables
+	SlowErrorMs  int                  `mapstructure:"slow_error_ms"`  // 0 disables
+	MaxBodyBytes int                  `mapstructure:"max_body_bytes"` // Cap for captured bodies
+	Routes       []HTTPLogRouteConfig `mapstructure:"routes"`         // First match wins
+}
+
+// HTTPLogRouteConfig overrides sampling and enables capture for matching requests
+type HTTPLogRouteConfig struct {
+	Path           string   `mapstructure:"path"`    // Glob
+	Methods        []string `mapstructure:"methods"` // Empty matches every method
+	SampleRate     float64  `mapstructure:"sample_rate"`
+	CaptureHeaders bool     `mapstructure:"capture_headers"`
+	CaptureBody    bool     `mapstructure:"capture_body"`
+}
+
+// LogSinkConfig configures an extra log destination behind a bounded buffer
+type LogSinkConfig struct {
+	Name       string `mapstructure:"name"`
+	Enabled    bool   `mapstructure:"enabled"`
+	Type       string `mapstructure:"type"`        // syslog, http or otlp
+	Level      string `mapstructure:"level"`       // Minimum level for this sink
+	Format     string `mapstructure:"format"`      // json or console
+	BufferSize int    `mapstructure:"buffer_size"` // Entries queued before new ones are dropped
+
+	// syslog
+	Network  string `mapstructure:"network"` // udp, tcp or unix
+	Address  string `mapstructure:"address"`
+	Tag      string `mapstructure:"tag"`
+	Facility string `mapstructure:"facility"`
+
+	// http and otlp
+	URL           string            `mapstructure:"url"`
+	Protocol      string            `mapstructure:"protocol"` // http only: json or loki
+	Headers       map[string]string `mapstructure:"headers"`
+	Labels        map[string]string `mapstructure:"labels"` // Loki stream labels or OTLP resource attributes
+	BatchSize     int               `mapstructure:"batch_size"`
+	FlushInterval int               `mapstructure:"flush_interval"` // Milliseconds
+	Timeout       int               `mapstructure:"timeout"`        // Seconds
+	MaxRetries    int               `mapstructure:"max_retries"`
+}
+
+type RedactionConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	Keys           []string `mapstructure:"keys"`            // Name fragments whose values are masked
+	Patterns       []string `mapstructure:"patterns"`        // Built-in value patterns: jwt, email, card
+	CustomPatterns []string `mapstructure:"custom_patterns"` // Extra regular expressions
+}
+
+// SamplingConfig limits repeated entries per level and message; error and
+// above are never sampled
+type SamplingConfig struct {
+	Enabled    bool                    `mapstructure:"enabled"`
+	Tick       int                     `mapstructure:"tick"`       // Seconds per sampling window
+	Initial    int                     `mapstructure:"initial"`    // Entries per message logged each tick
+	Thereafter int                     `mapstructure:"thereafter"` // Then every Mth entry is logged
+	Levels     map[string]SamplingRule `mapstructure:"levels"`     // Per-level overrides
+}
+
+type SamplingRule struct {
+	Initial    int `mapstructure:"initial"` // 0 disables sampling for the level
+	Thereafter int `mapstructure:"thereafter"`
+}
+
+type LevelControlConfig struct {
+	SignalLevel string `mapstructure:"signal_level"` // Level applied on SIGUSR1
+	SignalTTL   int    `mapstructure:"signal_ttl"`   // Seconds before SIGUSR1 reverts; 0 keeps it
+	MaxTTL      int    `mapstructure:"max_ttl"`      // Longest TTL accepted by the admin API
 }
 
 type FileLoggingConfig struct {
@@ -69,6 +180,24 @@ type FileLoggingConfig struct {
 	MaxAge        int    `mapstructure:"max_age"`
 	Compress      bool   `mapstructure:"compress"`
 	SeparateFiles bool   `mapstructure:"separate_files"`
+
+	Async  AsyncFileConfig   `mapstructure:"async"`
+	Routes []FileRouteConfig `mapstructure:"routes"` // Replace the app.log/separate files layout when set
+}
+
+type FileRouteConfig struct {
+	File     string `mapstructure:"file"`
+	MinLevel string `mapstructure:"min_level"` // Empty means no lower bound
+	MaxLevel string `mapstructure:"max_level"` // Empty means no upper bound
+	Format   string `mapstructure:"format"`    // json or console
+	Logger   string `mapstructure:"logger"`    // Only entries from this named logger and its children
+}
+
+type AsyncFileConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	BufferSize    int    `mapstructure:"buffer_size"`    // Entries held in memory
+	FlushInterval int    `mapstructure:"flush_interval"` // Milliseconds
+	Overflow      string `mapstructure:"overflow"`       // block, drop_oldest or drop_newest
 }
 
 type SwaggerConfig struct {
@@ -101,6 +230,115 @@ type RateLimitConfig struct {
 	RequestsPerMinute int  `mapstructure:"requests_per_minute"`
 }
 
+type IdempotencyConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Store        string `mapstructure:"store"`          // memory or redis
+	TTL          int    `mapstructure:"ttl"`            // Seconds a stored response is replayed
+	LockTTL      int    `mapstructure:"lock_ttl"`       // Seconds an in-flight key stays locked
+	MaxBodyBytes int64  `mapstructure:"max_body_bytes"` // Largest request body fingerprinted
+}
+
+type HTTPCacheConfig struct {
+	ETag          bool                `mapstructure:"etag"`
+	ResponseCache ResponseCacheConfig `mapstructure:"response_cache"`
+}
+
+type ResponseCacheConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Store       string   `mapstructure:"store"` // memory or redis
+	TTL         int      `mapstructure:"ttl"`   // Seconds
+	VaryHeaders []string `mapstructure:"vary_headers"`
+}
+
+type CompressionConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MinSize      int      `mapstructure:"min_size"`      // Bytes
+	Encodings    []string `mapstructure:"encodings"`     // Server preference order
+	ContentTypes []string `mapstructure:"content_types"` // Allowlist, supports type/* wildcards
+}
+
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	ServiceName string            `mapstructure:"service_name"`
+	Exporter    string            `mapstructure:"exporter"` // otlp, stdout or none
+	Endpoint    string            `mapstructure:"endpoint"` // OTLP/HTTP host:port
+	URLPath     string            `mapstructure:"url_path"`
+	Insecure    bool              `mapstructure:"insecure"`
+	Headers     map[string]string `mapstructure:"headers"`
+	SampleRatio float64           `mapstructure:"sample_ratio"`
+}
+
+// AdminConfig configures the operational API, served on its own listener.
+// Callers need the token or, when client_ca_file is set, a verified client
+// certificate; the listener does not start without either.
+type AdminConfig struct {
+	Enabled bool           `mapstructure:"enabled"`
+	Host    string         `mapstructure:"host"`
+	Port    string         `mapstructure:"port"`
+	Token   string         `mapstructure:"token"` // Bearer token
+	TLS     AdminTLSConfig `mapstructure:"tls"`
+}
+
+type AdminTLSConfig struct {
+	CertFile     string `mapstructure:"cert_file"`
+	KeyFile      string `mapstructure:"key_file"`
+	ClientCAFile string `mapstructure:"client_ca_file"` // Enables mTLS
+}
+
+type AuditConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	File    string `mapstructure:"file"` // Append-only, hash-chained; never rotate it externally
+}
+
+// AccessLogConfig configures the HTTP access log, written to its own rotated
+// file regardless of the application log settings
+type AccessLogConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	Format     string   `mapstructure:"format"`     // common, combined, w3c or template
+	Template   string   `mapstructure:"template"`   // Used when format is template
+	W3CFields  []string `mapstructure:"w3c_fields"` // Used when format is w3c
+	File       string   `mapstructure:"file"`
+	MaxSize    int      `mapstructure:"max_size"` // MB
+	MaxBackups int      `mapstructure:"max_backups"`
+	MaxAge     int      `mapstructure:"max_age"` // Days
+	Compress   bool     `mapstructure:"compress"`
+}
+
+// RecorderConfig controls capture of request/response pairs for replaying
+type RecorderConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	Store         string   `mapstructure:"store"`          // memory or directory
+	Directory     string   `mapstructure:"directory"`      // Used by the directory store
+	MaxRecordings int      `mapstructure:"max_recordings"` // Oldest are dropped beyond this
+	SampleRate    float64  `mapstructure:"sample_rate"`    // Share of matching requests recorded
+	RecordErrors  bool     `mapstructure:"record_errors"`  // Always record 5xx responses
+	MaxBodyBytes  int      `mapstructure:"max_body_bytes"`
+	Paths         []string `mapstructure:"paths"`      // Globs; empty records every path
+	SkipPaths     []string `mapstructure:"skip_paths"` // Globs
+}
+
+// ProfilingConfig guards the profiling endpoints of the admin API and the
+// continuous profiler; both are off by default
+type ProfilingConfig struct {
+	Endpoints   bool                      `mapstructure:"endpoints"`    // pprof, trace capture and goroutine dumps under /debug
+	MaxDuration int                       `mapstructure:"max_duration"` // Seconds; caps on-demand CPU profiles and traces
+	Continuous  ContinuousProfilingConfig `mapstructure:"continuous"`
+}
+
+type ContinuousProfilingConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Directory   string `mapstructure:"directory"`
+	Interval    int    `mapstructure:"interval"`     // Seconds between captures
+	CPUDuration int    `mapstructure:"cpu_duration"` // Seconds of each CPU profile; 0 captures heap only
+	Retention   int    `mapstructure:"retention"`    // Profiles of each kind kept
+}
+
+// Settings returns every setting by its config key, for the admin config
+// dump. Callers must redact it.
+func Settings() map[string]interface{} {
+	return viper.AllSettings()
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -127,14 +365,54 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := validate(&config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return &config, nil
 }
 
+// validate rejects settings that would only fail once a listener starts
+func validate(config *Config) error {
+	if !config.Admin.Enabled {
+		return nil
+	}
+	if config.Admin.Port == config.Server.Port {
+		return fmt.Errorf("admin.port %s is already used by server.port", config.Admin.Port)
+	}
+	if config.Metrics.Enabled && config.Admin.Port == config.Metrics.Port {
+		return fmt.Errorf("admin.port %s is already used by metrics.port", config.Admin.Port)
+	}
+	return nil
+}
+
+// Watch re-reads the config file whenever it changes and passes the result
+// to onChange. Only settings that are safe to change at runtime should be
+// applied from the reloaded config; Load must have been called first.
+func Watch(onChange func(*Config, error)) {
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		var config Config
+		if err := viper.Unmarshal(&config); err != nil {
+			onChange(nil, fmt.Errorf("failed to unmarshal config from %s: %w", event.Name, err))
+			return
+		}
+		onChange(&config, nil)
+	})
+	viper.WatchConfig()
+}
+
 func setDefaults() {
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.environment", "development")
+	viper.SetDefault("server.runtime.auto_maxprocs", true)
+	viper.SetDefault("server.runtime.min_maxprocs", 1)
+	viper.SetDefault("server.runtime.auto_memlimit", true)
+	viper.SetDefault("server.runtime.memlimit_ratio", 0.9)
+	viper.SetDefault("health.check_timeout", 2)
+	viper.SetDefault("health.heartbeat_interval", 1)
+	viper.SetDefault("health.heartbeat_timeout", 10)
+	viper.SetDefault("health.drain_delay", 5)
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.sslmode", "disable")
@@ -144,6 +422,47 @@ func setDefaults() {
 	viper.SetDefault("auth.jwt_expiration", 3600)
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("logging.level_control.signal_level", "debug")
+	viper.SetDefault("logging.level_control.signal_ttl", 600)
+	viper.SetDefault("logging.level_control.max_ttl", 86400)
+	viper.SetDefault("logging.file.async.buffer_size", 8192)
+	viper.SetDefault("logging.file.async.flush_interval", 1000)
+	viper.SetDefault("logging.file.async.overflow", "block")
+	viper.SetDefault("logging.redaction.enabled", true)
+	viper.SetDefault("logging.http.skip_paths", []string{"/health", "/healthz", "/ping", "/metrics", "/favicon.ico",
+		"/api/v1/health", "/api/v1/heartbeat", "/api/v1/startup", "/api/v1/ready", "/api/v1/live"})
+	viper.SetDefault("logging.http.skip_globs", []string{"/static/**", "/assets/**", "/**/*.css", "/**/*.js", "/**/*.ico"})
+	viper.SetDefault("logging.http.max_body_bytes", 4096)
+	viper.SetDefault("logging.sampling.tick", 1)
+	viper.SetDefault("logging.sampling.initial", 100)
+	viper.SetDefault("logging.sampling.thereafter", 100)
+	viper.SetDefault("audit.file", "./logs/audit.log")
+	viper.SetDefault("access_log.format", "combined")
+	viper.SetDefault("recorder.store", "memory")
+	viper.SetDefault("recorder.directory", "./logs/recordings")
+	viper.SetDefault("recorder.max_recordings", 100)
+	viper.SetDefault("recorder.max_body_bytes", 65536)
+	viper.SetDefault("admin.host", "127.0.0.1")
+	viper.SetDefault("admin.port", "9091")
+	viper.SetDefault("profiling.max_duration", 60)
+	viper.SetDefault("profiling.continuous.directory", "./logs/profiles")
+	viper.SetDefault("profiling.continuous.interval", 300)
+	viper.SetDefault("profiling.continuous.cpu_duration", 10)
+	viper.SetDefault("profiling.continuous.retention", 48)
+	viper.SetDefault("access_log.file", "./logs/access.log")
+	viper.SetDefault("access_log.max_size", 100)
+	viper.SetDefault("idempotency.store", "memory")
+	viper.SetDefault("idempotency.ttl", 86400)
+	viper.SetDefault("idempotency.lock_ttl", 60)
+	viper.SetDefault("idempotency.max_body_bytes", 1048576)
+	viper.SetDefault("http_cache.etag", true)
+	viper.SetDefault("http_cache.response_cache.store", "memory")
+	viper.SetDefault("http_cache.response_cache.ttl", 60)
+	viper.SetDefault("compression.min_size", 1024)
+	viper.SetDefault("tracing.service_name", "go-clean-template")
+	viper.SetDefault("tracing.exporter", "otlp")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
 }
 
 func loadEnvFile() error {
@@ -204,4 +523,12 @@ func mapEnvVariables() {
 	_ = viper.BindEnv("logging.level", "LOG_LEVEL")
 	_ = viper.BindEnv("logging.format", "LOG_FORMAT")
 	_ = viper.BindEnv("logging.file.separate_files", "LOG_SEPARATE_FILES")
+
+	_ = viper.BindEnv("admin.token", "ADMIN_TOKEN")
+	_ = viper.BindEnv("admin.host", "ADMIN_HOST")
+	_ = viper.BindEnv("admin.port", "ADMIN_PORT")
+
+	_ = viper.BindEnv("tracing.enabled", "TRACING_ENABLED")
+	_ = viper.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+	_ = viper.BindEnv("tracing.endpoint", "TRACING_ENDPOINT")
 }
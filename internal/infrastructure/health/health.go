@@ -0,0 +1,249 @@
+// Package health implements the startup, liveness and readiness probes.
+// Startup passes once every startup task is done, liveness fails when an
+// internal loop stops beating its heartbeat, and readiness runs the
+// dependency checks and fails while the instance drains.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+const (
+	StatusPass    = "pass"
+	StatusWarn    = "warn" // A non-critical check failed
+	StatusFail    = "fail"
+	StatusPending = "pending" // A startup task is still running
+)
+
+// schedulerHeartbeat is beaten by the probes' own goroutine; it goes stale
+// when goroutines stop being scheduled
+const schedulerHeartbeat = "scheduler"
+
+// Check reports whether a dependency is usable
+type Check struct {
+	Name string
+	// Critical checks make readiness fail; others only downgrade it to warn
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of one check, startup task or heartbeat
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report is the outcome of one probe
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Passing reports whether the probe should answer 200
+func (r Report) Passing() bool {
+	return r.Status != StatusFail
+}
+
+type startupTask struct {
+	name  string
+	done  bool
+	err   error
+	begun time.Time
+	took  time.Duration
+}
+
+// Probes holds the state behind the three probes
+type Probes struct {
+	checkTimeout      time.Duration
+	heartbeatInterval time.Duration
+	heartbeatTimeout  time.Duration
+	watchdog          Watchdog
+	draining          atomic.Bool
+	// started latches once startup passed, like the kubelet stops probing
+	started atomic.Bool
+
+	mu     sync.Mutex
+	checks []Check
+	tasks  []*startupTask
+}
+
+func New(cfg config.HealthConfig) *Probes {
+	probes := &Probes{
+		checkTimeout:      time.Duration(cfg.CheckTimeout) * time.Second,
+		heartbeatInterval: time.Duration(cfg.HeartbeatInterval) * time.Second,
+		heartbeatTimeout:  time.Duration(cfg.HeartbeatTimeout) * time.Second,
+	}
+	if probes.heartbeatInterval <= 0 {
+		probes.heartbeatInterval = time.Second
+	}
+	// A single late tick must not fail liveness
+	probes.heartbeatTimeout = max(probes.heartbeatTimeout, 3*probes.heartbeatInterval)
+	return probes
+}
+
+// Run beats the scheduler heartbeat until ctx is done
+func (p *Probes) Run(ctx context.Context) {
+	heartbeat := p.watchdog.Register(schedulerHeartbeat, p.heartbeatTimeout)
+
+	ticker := time.NewTicker(p.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			heartbeat.Beat()
+		}
+	}
+}
+
+// Watchdog lets long-running loops register heartbeats checked by liveness
+func (p *Probes) Watchdog() *Watchdog {
+	return &p.watchdog
+}
+
+// AddCheck registers a readiness check
+func (p *Probes) AddCheck(check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, check)
+}
+
+// StartupTask registers initialization work, such as migrations or cache
+// warm-up, that startup waits for. Call the returned function when it
+// finishes; an error keeps startup failing so the pod is restarted.
+func (p *Probes) StartupTask(name string) func(error) {
+	task := &startupTask{name: name, begun: time.Now()}
+
+	p.mu.Lock()
+	p.tasks = append(p.tasks, task)
+	p.mu.Unlock()
+
+	return func(err error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		task.done, task.err, task.took = true, err, time.Since(task.begun)
+	}
+}
+
+// Drain makes readiness fail so load balancers stop routing here before
+// the server shuts down
+func (p *Probes) Drain() {
+	p.draining.Store(true)
+}
+
+// Startup passes once every startup task finished without error
+func (p *Probes) Startup() Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := Report{Status: StatusPass, Checks: make([]Result, 0, len(p.tasks))}
+	for _, task := range p.tasks {
+		result := Result{Name: task.name, Status: StatusPass, Critical: true}
+		switch {
+		case !task.done:
+			result.Status = StatusPending
+			result.Duration = time.Since(task.begun).Round(time.Millisecond).String()
+			report.Status = StatusFail
+		case task.err != nil:
+			result.Status = StatusFail
+			result.Error = task.err.Error()
+			report.Status = StatusFail
+		default:
+			result.Duration = task.took.Round(time.Millisecond).String()
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	if report.Status == StatusPass {
+		p.started.Store(true)
+	}
+	return report
+}
+
+// Liveness fails when a heartbeat is stale. It never looks at dependencies:
+// restarting the process does not fix a database outage.
+func (p *Probes) Liveness() Report {
+	return summarize(p.watchdog.results())
+}
+
+// Readiness fails before startup completed, while draining, and when a
+// critical dependency check fails. Checks run concurrently, each bounded by
+// the check timeout.
+func (p *Probes) Readiness(ctx context.Context) Report {
+	results := []Result{
+		p.stateResult("startup", p.started.Load() || p.Startup().Passing(), "startup has not completed"),
+		p.stateResult("drain", !p.draining.Load(), "instance is draining"),
+	}
+
+	p.mu.Lock()
+	checks := append([]Check(nil), p.checks...)
+	p.mu.Unlock()
+
+	checked := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checked[i] = p.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	return summarize(append(results, checked...))
+}
+
+func (p *Probes) stateResult(name string, ok bool, reason string) Result {
+	if ok {
+		return Result{Name: name, Status: StatusPass, Critical: true}
+	}
+	return Result{Name: name, Status: StatusFail, Critical: true, Error: reason}
+}
+
+func (p *Probes) run(ctx context.Context, check Check) Result {
+	if p.checkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.checkTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := Result{
+		Name:     check.Name,
+		Status:   StatusPass,
+		Critical: check.Critical,
+		Duration: time.Since(start).Round(time.Microsecond).String(),
+	}
+	if err != nil {
+		result.Status = StatusWarn
+		if check.Critical {
+			result.Status = StatusFail
+		}
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// summarize fails the report on a failed critical result and warns on any
+// other failure
+func summarize(results []Result) Report {
+	report := Report{Status: StatusPass, Checks: results}
+	for _, result := range results {
+		switch {
+		case result.Status == StatusFail && result.Critical:
+			report.Status = StatusFail
+		case result.Status != StatusPass && report.Status == StatusPass:
+			report.Status = StatusWarn
+		}
+	}
+	return report
+}
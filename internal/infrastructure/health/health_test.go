@@ -0,0 +1,172 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+// errPending leaves a startup task running
+var errPending = errors.New("pending")
+
+func TestStartup(t *testing.T) {
+	tests := []struct {
+		name       string
+		tasks      []error
+		wantStatus string
+	}{
+		{name: "no tasks", wantStatus: StatusPass},
+		{name: "finished", tasks: []error{nil, nil}, wantStatus: StatusPass},
+		{name: "pending", tasks: []error{nil, errPending}, wantStatus: StatusFail},
+		{name: "failed", tasks: []error{errors.New("migration failed")}, wantStatus: StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := New(config.HealthConfig{})
+			for i, err := range tt.tasks {
+				done := probes.StartupTask(fmt.Sprintf("task-%d", i))
+				if !errors.Is(err, errPending) {
+					done(err)
+				}
+			}
+
+			if got := probes.Startup().Status; got != tt.wantStatus {
+				t.Errorf("startup = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	failing := func(context.Context) error { return errors.New("down") }
+
+	tests := []struct {
+		name       string
+		setup      func(p *Probes)
+		wantStatus string
+		wantFailed string
+	}{
+		{name: "started", wantStatus: StatusPass},
+		{
+			name:       "startup pending",
+			setup:      func(p *Probes) { p.StartupTask("warm-up") },
+			wantStatus: StatusFail,
+			wantFailed: "startup",
+		},
+		{
+			name: "startup latched",
+			setup: func(p *Probes) {
+				p.Startup()
+				// A task registered after startup passed does not undo it
+				p.StartupTask("late")
+			},
+			wantStatus: StatusPass,
+		},
+		{
+			name:       "draining",
+			setup:      func(p *Probes) { p.Drain() },
+			wantStatus: StatusFail,
+			wantFailed: "drain",
+		},
+		{
+			name:       "critical check fails",
+			setup:      func(p *Probes) { p.AddCheck(Check{Name: "database", Critical: true, Run: failing}) },
+			wantStatus: StatusFail,
+			wantFailed: "database",
+		},
+		{
+			name:       "optional check fails",
+			setup:      func(p *Probes) { p.AddCheck(Check{Name: "cache", Run: failing}) },
+			wantStatus: StatusWarn,
+			wantFailed: "cache",
+		},
+		{
+			name:       "check times out",
+			setup:      func(p *Probes) { p.AddCheck(Check{Name: "database", Critical: true, Run: slow}) },
+			wantStatus: StatusFail,
+			wantFailed: "database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := New(config.HealthConfig{})
+			probes.checkTimeout = 10 * time.Millisecond
+			if tt.setup != nil {
+				tt.setup(probes)
+			}
+
+			report := probes.Readiness(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Fatalf("readiness = %s, want %s: %+v", report.Status, tt.wantStatus, report.Checks)
+			}
+			for _, result := range report.Checks {
+				failed := result.Status != StatusPass
+				if want := result.Name == tt.wantFailed; failed != want {
+					t.Errorf("check %s status %s, want failed %t", result.Name, result.Status, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	tests := []struct {
+		name       string
+		age        time.Duration
+		wantStatus string
+	}{
+		{name: "fresh heartbeat", age: 0, wantStatus: StatusPass},
+		{name: "late but within timeout", age: 2 * time.Second, wantStatus: StatusPass},
+		{name: "stale heartbeat", age: time.Minute, wantStatus: StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := New(config.HealthConfig{HeartbeatInterval: 1, HeartbeatTimeout: 10})
+			heartbeat := probes.Watchdog().Register("worker", probes.heartbeatTimeout)
+			heartbeat.last.Store(time.Now().Add(-tt.age).UnixNano())
+
+			report := probes.Liveness()
+			if report.Status != tt.wantStatus {
+				t.Errorf("liveness = %s, want %s: %+v", report.Status, tt.wantStatus, report.Checks)
+			}
+		})
+	}
+}
+
+func TestRunKeepsSchedulerHeartbeat(t *testing.T) {
+	probes := New(config.HealthConfig{HeartbeatInterval: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		probes.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(probes.Liveness().Checks) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Run did not register the scheduler heartbeat")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	report := probes.Liveness()
+	if report.Status != StatusPass || report.Checks[0].Name != schedulerHeartbeat {
+		t.Errorf("unexpected liveness %+v", report)
+	}
+}
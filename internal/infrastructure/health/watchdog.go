@@ -0,0 +1,56 @@
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat is beaten by a loop that must keep running
+type Heartbeat struct {
+	name    string
+	timeout time.Duration
+	last    atomic.Int64 // Unix nanoseconds
+}
+
+// Beat records that the loop made progress
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Watchdog fails liveness when a registered heartbeat is older than its
+// timeout, i.e. the loop beating it is wedged
+type Watchdog struct {
+	mu    sync.Mutex
+	beats []*Heartbeat
+}
+
+// Register adds a heartbeat, counted as beaten now
+func (w *Watchdog) Register(name string, timeout time.Duration) *Heartbeat {
+	heartbeat := &Heartbeat{name: name, timeout: timeout}
+	heartbeat.Beat()
+
+	w.mu.Lock()
+	w.beats = append(w.beats, heartbeat)
+	w.mu.Unlock()
+	return heartbeat
+}
+
+func (w *Watchdog) results() []Result {
+	w.mu.Lock()
+	beats := append([]*Heartbeat(nil), w.beats...)
+	w.mu.Unlock()
+
+	now := time.Now()
+	results := make([]Result, 0, len(beats))
+	for _, heartbeat := range beats {
+		age := now.Sub(time.Unix(0, heartbeat.last.Load()))
+		result := Result{Name: heartbeat.name, Status: StatusPass, Critical: true, Duration: age.Round(time.Millisecond).String()}
+		if age > heartbeat.timeout {
+			result.Status = StatusFail
+			result.Error = "no heartbeat for " + result.Duration
+		}
+		results = append(results, result)
+	}
+	return results
+}
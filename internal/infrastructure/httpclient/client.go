@@ -0,0 +1,24 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"go-clean-template/internal/infrastructure/tracing"
+)
+
+// DefaultTimeout bounds outbound requests made with New
+const DefaultTimeout = 30 * time.Second
+
+// New creates the HTTP client to use for calls to other services. Requests
+// made with it carry the caller's trace context and correlation ID.
+func New(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: tracing.NewTransport(NewCorrelationTransport(http.DefaultTransport)),
+	}
+}
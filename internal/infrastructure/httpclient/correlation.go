@@ -0,0 +1,32 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// CorrelationTransport forwards the correlation ID of the request context to
+// downstream services unless the caller already set the header
+type CorrelationTransport struct {
+	Base http.RoundTripper
+}
+
+func NewCorrelationTransport(base http.RoundTripper) *CorrelationTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &CorrelationTransport{Base: base}
+}
+
+func (t *CorrelationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := requestctx.CorrelationID(req.Context())
+	if id == "" || req.Header.Get(requestctx.CorrelationHeader) != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(requestctx.CorrelationHeader, id)
+	return t.Base.RoundTrip(req)
+}
@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy decides what happens when an async writer's buffer is full
+type OverflowPolicy string
+
+const (
+	OverflowBlock      OverflowPolicy = "block"       // Wait for space; no loss
+	OverflowDropOldest OverflowPolicy = "drop_oldest" // Discard the oldest buffered entry
+	OverflowDropNewest OverflowPolicy = "drop_newest" // Discard the entry being written
+
+	defaultAsyncBufferSize    = 8192
+	defaultAsyncFlushInterval = time.Second
+	asyncWriteBufferBytes     = 256 * 1024
+)
+
+type AsyncConfig struct {
+	BufferSize    int // Entries held in memory
+	FlushInterval time.Duration
+	Overflow      OverflowPolicy
+}
+
+// ParseOverflowPolicy validates a configured overflow policy; empty means block
+func ParseOverflowPolicy(policy string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(strings.ToLower(strings.TrimSpace(policy))); p {
+	case "":
+		return OverflowBlock, nil
+	case OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy %q", policy)
+	}
+}
+
+// asyncWriters tracks writers by name for Metrics
+var asyncWriters sync.Map // name -> *AsyncWriter
+
+func asyncWriterStats() map[string]SinkStats {
+	stats := make(map[string]SinkStats)
+	asyncWriters.Range(func(key, value interface{}) bool {
+		w := value.(*AsyncWriter)
+		stats[key.(string)] = SinkStats{
+			Sent:    w.written.Load(),
+			Dropped: w.dropped.Load(),
+			Failed:  w.failed.Load(),
+		}
+		return true
+	})
+	return stats
+}
+
+// AsyncWriter moves writes off the caller's goroutine: entries go into a
+// ring buffer and a background goroutine writes them through a buffered
+// writer that is flushed periodically and on Sync
+type AsyncWriter struct {
+	out      io.Writer
+	policy   OverflowPolicy
+	interval time.Duration
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	ring     [][]byte
+	head     int
+	count    int
+	// Entries are numbered as they are enqueued. dequeued counts those taken
+	// off the ring, written or dropped; flushRequested is the highest number
+	// a flush was asked for and flushed the highest one a flush covered. Sync
+	// waits for its own target only, so it returns under continuous writes.
+	enqueued       uint64
+	dequeued       uint64
+	flushRequested uint64
+	flushed        uint64
+	flushErr       error
+	drained        *sync.Cond
+	closed         bool
+	done           chan struct{}
+
+	written atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewAsyncWriter starts the background writer for out. name identifies the
+// writer in Metrics.
+func NewAsyncWriter(name string, out io.Writer, cfg AsyncConfig) *AsyncWriter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAsyncBufferSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultAsyncFlushInterval
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = OverflowBlock
+	}
+
+	w := &AsyncWriter{
+		out:      out,
+		policy:   cfg.Overflow,
+		interval: cfg.FlushInterval,
+		ring:     make([][]byte, cfg.BufferSize),
+		done:     make(chan struct{}),
+	}
+	w.notEmpty = sync.NewCond(&w.mu)
+	w.notFull = sync.NewCond(&w.mu)
+	w.drained = sync.NewCond(&w.mu)
+
+	if previous, loaded := asyncWriters.Swap(name, w); loaded {
+		_ = previous.(*AsyncWriter).Close()
+	}
+	go w.run()
+	go w.tick()
+	return w
+}
+
+// Write copies p into the ring buffer; zap reuses its buffers after Write returns
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	entry := append([]byte(nil), p...)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, fmt.Errorf("async log writer is closed")
+	}
+
+	if w.count == len(w.ring) {
+		switch w.policy {
+		case OverflowDropNewest:
+			w.dropped.Add(1)
+			return len(p), nil
+		case OverflowDropOldest:
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dequeued++
+			w.dropped.Add(1)
+		default:
+			for w.count == len(w.ring) && !w.closed {
+				w.notFull.Wait()
+			}
+			if w.closed {
+				return 0, fmt.Errorf("async log writer is closed")
+			}
+		}
+	}
+
+	w.ring[(w.head+w.count)%len(w.ring)] = entry
+	w.count++
+	w.enqueued++
+	w.notEmpty.Signal()
+	return len(p), nil
+}
+
+// Sync blocks until every entry written before the call reached the
+// underlying writer, then syncs it when it supports that. Entries written
+// after Sync was called do not extend the wait.
+func (w *AsyncWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	target := w.enqueued
+	w.requestFlushLocked(target)
+	for w.flushed < target && !w.closed {
+		w.drained.Wait()
+	}
+	return w.flushErr
+}
+
+// requestFlushLocked asks the background goroutine to flush once every entry
+// up to seq has been written
+func (w *AsyncWriter) requestFlushLocked(seq uint64) {
+	if seq > w.flushRequested {
+		w.flushRequested = seq
+		w.notEmpty.Signal()
+	}
+}
+
+// Close flushes what is buffered and stops the background goroutine
+func (w *AsyncWriter) Close() error {
+	err := w.Sync()
+
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+		w.notEmpty.Broadcast()
+		w.notFull.Broadcast()
+		w.drained.Broadcast()
+	}
+	w.mu.Unlock()
+	return err
+}
+
+func (w *AsyncWriter) tick() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			w.requestFlushLocked(w.enqueued)
+			w.mu.Unlock()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// run drains the ring buffer in batches and flushes once a requested
+// sequence number has been written
+func (w *AsyncWriter) run() {
+	buffered := bufio.NewWriterSize(w.out, asyncWriteBufferBytes)
+	batch := make([][]byte, 0, 256)
+
+	for {
+		w.mu.Lock()
+		for w.count == 0 && w.flushRequested <= w.flushed && !w.closed {
+			w.notEmpty.Wait()
+		}
+		if w.closed {
+			w.mu.Unlock()
+			return
+		}
+		for w.count > 0 && len(batch) < cap(batch) {
+			batch = append(batch, w.ring[w.head])
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+		}
+		w.dequeued += uint64(len(batch))
+		upTo := w.dequeued
+		flush := w.flushRequested > w.flushed && upTo >= w.flushRequested
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+
+		for _, entry := range batch {
+			if _, err := buffered.Write(entry); err != nil {
+				w.failed.Add(1)
+				continue
+			}
+			w.written.Add(1)
+		}
+
+		if flush {
+			syncErr := buffered.Flush()
+			if syncer, ok := w.out.(zapcore.WriteSyncer); ok && syncErr == nil {
+				syncErr = syncer.Sync()
+			}
+
+			w.mu.Lock()
+			w.flushed = upTo
+			w.flushErr = syncErr
+			w.drained.Broadcast()
+			w.mu.Unlock()
+		}
+
+		clear(batch)
+		batch = batch[:0]
+	}
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// stallingWriter blocks for every N entries written, like a disk under
+// contention. The lock serialises writers as a file would.
+type stallingWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	stall   time.Duration
+	every   int64
+	entries int64
+}
+
+func (w *stallingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.every > 0 {
+		before := w.entries / w.every
+		w.entries += int64(bytes.Count(p, []byte{'\n'}))
+		if stalls := w.entries/w.every - before; stalls > 0 {
+			time.Sleep(time.Duration(stalls) * w.stall)
+		}
+	}
+	return w.out.Write(p)
+}
+
+func (w *stallingWriter) Sync() error {
+	return nil
+}
+
+func TestAsyncWriterSyncUnderContinuousWrites(t *testing.T) {
+	out := &stallingWriter{out: io.Discard, stall: time.Millisecond, every: 100}
+	w := NewAsyncWriter("test-async-continuous", out, AsyncConfig{BufferSize: 64, FlushInterval: time.Hour})
+	t.Cleanup(func() {
+		_ = w.Close()
+		asyncWriters.Delete("test-async-continuous")
+	})
+
+	stop := make(chan struct{})
+	var writers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_, _ = w.Write([]byte("entry\n"))
+				}
+			}
+		}()
+	}
+	defer func() {
+		close(stop)
+		writers.Wait()
+	}()
+
+	for i := 0; i < 5; i++ {
+		synced := make(chan error, 1)
+		go func() { synced <- w.Sync() }()
+
+		select {
+		case err := <-synced:
+			if err != nil {
+				t.Fatalf("sync: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Sync did not return while writes continued")
+		}
+	}
+}
+
+func TestAsyncWriterSyncFlushesPriorWrites(t *testing.T) {
+	var out bytes.Buffer
+	w := NewAsyncWriter("test-async-flush", zapcore.AddSync(&out), AsyncConfig{FlushInterval: time.Hour})
+	t.Cleanup(func() {
+		_ = w.Close()
+		asyncWriters.Delete("test-async-flush")
+	})
+
+	for i := 0; i < 1000; i++ {
+		_, _ = w.Write([]byte("entry\n"))
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if got := bytes.Count(out.Bytes(), []byte{'\n'}); got != 1000 {
+		t.Fatalf("expected 1000 entries after Sync, got %d", got)
+	}
+}
+
+// benchmarkLogger logs from parallel goroutines through a writer that stalls
+// every 500 entries and reports the tail latency of individual calls
+func benchmarkLogger(b *testing.B, async bool) {
+	var writer zapcore.WriteSyncer = &stallingWriter{out: io.Discard, stall: 2 * time.Millisecond, every: 500}
+	if async {
+		name := "bench-" + b.Name()
+		asyncWriter := NewAsyncWriter(name, writer, AsyncConfig{})
+		b.Cleanup(func() {
+			_ = asyncWriter.Close()
+			asyncWriters.Delete(name)
+		})
+		writer = asyncWriter
+	}
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), writer, zapcore.InfoLevel)
+	log := NewFromZap(zap.New(core))
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		seq       atomic.Int64
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		local := make([]time.Duration, 0, 1024)
+		for pb.Next() {
+			began := time.Now()
+			log.Info("request completed",
+				Int64("seq", seq.Add(1)),
+				String("method", "GET"),
+				String("path", "/api/v1/heartbeat"),
+				Int("status", 200),
+			)
+			local = append(local, time.Since(began))
+		}
+		mu.Lock()
+		latencies = append(latencies, local...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+	_ = log.Sync()
+
+	if len(latencies) == 0 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	for _, p := range []struct {
+		unit     string
+		quantile float64
+	}{{"p50-ns", 0.50}, {"p90-ns", 0.90}, {"p99-ns", 0.99}} {
+		b.ReportMetric(float64(latencies[int(float64(len(latencies)-1)*p.quantile)].Nanoseconds()), p.unit)
+	}
+	b.ReportMetric(float64(latencies[len(latencies)-1].Nanoseconds()), "max-ns")
+}
+
+func BenchmarkLoggerSync(b *testing.B) {
+	benchmarkLogger(b, false)
+}
+
+func BenchmarkLoggerAsync(b *testing.B) {
+	benchmarkLogger(b, true)
+}
@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelController owns the runtime log levels: a global zap.AtomicLevel plus
+// per-module overrides keyed by logger name (see Logger.Named). Temporary
+// changes revert to the configured levels once their TTL expires.
+type LevelController struct {
+	global  zap.AtomicLevel
+	modules atomic.Pointer[map[string]zapcore.Level]
+
+	mu             sync.Mutex
+	baseLevel      zapcore.Level
+	baseModules    map[string]zapcore.Level
+	timers         map[string]*time.Timer
+	expirations    map[string]time.Time
+	minLevelCached atomic.Int32
+}
+
+// LevelSnapshot describes the active levels
+type LevelSnapshot struct {
+	Level       string               `json:"level"`
+	Modules     map[string]string    `json:"modules,omitempty"`
+	Expirations map[string]time.Time `json:"expirations,omitempty"`
+}
+
+// globalLevelKey identifies the global level in timers and expirations
+const globalLevelKey = ""
+
+// NewLevelController creates a controller with the configured global level
+// and module overrides
+func NewLevelController(level string, modules map[string]string) (*LevelController, error) {
+	c := &LevelController{
+		global:      zap.NewAtomicLevel(),
+		timers:      make(map[string]*time.Timer),
+		expirations: make(map[string]time.Time),
+	}
+	if err := c.Configure(level, modules); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Configure replaces the configured levels, for example after a config
+// reload, and cancels any temporary change
+func (c *LevelController) Configure(level string, modules map[string]string) error {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	parsed := make(map[string]zapcore.Level, len(modules))
+	for module, moduleLevel := range modules {
+		l, err := zapcore.ParseLevel(moduleLevel)
+		if err != nil {
+			return fmt.Errorf("invalid log level %q for module %s: %w", moduleLevel, module, err)
+		}
+		parsed[strings.ToLower(module)] = l
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimersLocked()
+	c.baseLevel = lvl
+	c.baseModules = parsed
+	c.global.SetLevel(lvl)
+	c.storeModulesLocked(copyLevels(parsed))
+	return nil
+}
+
+// SetLevel changes the global level. A positive ttl reverts it to the
+// configured level afterwards.
+func (c *LevelController) SetLevel(level string, ttl time.Duration) error {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.global.SetLevel(lvl)
+	c.refreshMinLevelLocked()
+	c.scheduleRevertLocked(globalLevelKey, ttl)
+	return nil
+}
+
+// SetModuleLevel overrides the level for loggers named module or any of its
+// children. A positive ttl reverts it to the configured override, if any.
+func (c *LevelController) SetModuleLevel(module, level string, ttl time.Duration) error {
+	module = strings.ToLower(strings.TrimSpace(module))
+	if module == "" {
+		return fmt.Errorf("module name cannot be empty")
+	}
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modules := copyLevels(*c.modules.Load())
+	modules[module] = lvl
+	c.storeModulesLocked(modules)
+	c.scheduleRevertLocked(module, ttl)
+	return nil
+}
+
+// Reset restores the configured global and module levels
+func (c *LevelController) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimersLocked()
+	c.global.SetLevel(c.baseLevel)
+	c.storeModulesLocked(copyLevels(c.baseModules))
+}
+
+// Level returns the active global level
+func (c *LevelController) Level() zapcore.Level {
+	return c.global.Level()
+}
+
+// AtomicLevel exposes the global level, e.g. for zap's own HTTP handler
+func (c *LevelController) AtomicLevel() zap.AtomicLevel {
+	return c.global
+}
+
+// Snapshot returns the active levels and pending reverts
+func (c *LevelController) Snapshot() LevelSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := LevelSnapshot{Level: c.global.Level().String()}
+
+	modules := *c.modules.Load()
+	if len(modules) > 0 {
+		snapshot.Modules = make(map[string]string, len(modules))
+		for module, lvl := range modules {
+			snapshot.Modules[module] = lvl.String()
+		}
+	}
+	if len(c.expirations) > 0 {
+		snapshot.Expirations = make(map[string]time.Time, len(c.expirations))
+		for key, expiresAt := range c.expirations {
+			if key == globalLevelKey {
+				key = "global"
+			}
+			snapshot.Expirations[key] = expiresAt
+		}
+	}
+	return snapshot
+}
+
+// enabled reports whether an entry from the named logger passes the active levels
+func (c *LevelController) enabled(loggerName string, lvl zapcore.Level) bool {
+	if moduleLevel, ok := c.moduleLevel(loggerName); ok {
+		return lvl >= moduleLevel
+	}
+	return c.global.Enabled(lvl)
+}
+
+// minLevel is the most verbose level enabled anywhere, used as a cheap
+// pre-check before the per-module lookup
+func (c *LevelController) minLevel() zapcore.Level {
+	return zapcore.Level(c.minLevelCached.Load())
+}
+
+// moduleLevel finds the override for the longest dotted prefix of loggerName
+func (c *LevelController) moduleLevel(loggerName string) (zapcore.Level, bool) {
+	modules := *c.modules.Load()
+	if len(modules) == 0 || loggerName == "" {
+		return 0, false
+	}
+
+	name := strings.ToLower(loggerName)
+	for {
+		if lvl, ok := modules[name]; ok {
+			return lvl, true
+		}
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			return 0, false
+		}
+		name = name[:idx]
+	}
+}
+
+func (c *LevelController) scheduleRevertLocked(key string, ttl time.Duration) {
+	if timer, ok := c.timers[key]; ok {
+		timer.Stop()
+		delete(c.timers, key)
+		delete(c.expirations, key)
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.expirations[key] = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// A newer change for the same key replaced this timer
+		if c.timers[key] != timer {
+			return
+		}
+		delete(c.timers, key)
+		delete(c.expirations, key)
+		c.revertLocked(key)
+	})
+	c.timers[key] = timer
+}
+
+func (c *LevelController) revertLocked(key string) {
+	if key == globalLevelKey {
+		c.global.SetLevel(c.baseLevel)
+		c.refreshMinLevelLocked()
+		return
+	}
+
+	modules := copyLevels(*c.modules.Load())
+	if lvl, ok := c.baseModules[key]; ok {
+		modules[key] = lvl
+	} else {
+		delete(modules, key)
+	}
+	c.storeModulesLocked(modules)
+}
+
+func (c *LevelController) stopTimersLocked() {
+	for key, timer := range c.timers {
+		timer.Stop()
+		delete(c.timers, key)
+	}
+	for key := range c.expirations {
+		delete(c.expirations, key)
+	}
+}
+
+func (c *LevelController) storeModulesLocked(modules map[string]zapcore.Level) {
+	c.modules.Store(&modules)
+	c.refreshMinLevelLocked()
+}
+
+func (c *LevelController) refreshMinLevelLocked() {
+	lowest := c.global.Level()
+	for _, lvl := range *c.modules.Load() {
+		if lvl < lowest {
+			lowest = lvl
+		}
+	}
+	c.minLevelCached.Store(int32(lowest))
+}
+
+func copyLevels(levels map[string]zapcore.Level) map[string]zapcore.Level {
+	copied := make(map[string]zapcore.Level, len(levels))
+	for key, lvl := range levels {
+		copied[key] = lvl
+	}
+	return copied
+}
+
+// levelFilterCore applies the controller's levels in front of the output
+// cores, which are built to accept every level
+type levelFilterCore struct {
+	zapcore.Core
+	levels *LevelController
+}
+
+func newLevelFilterCore(core zapcore.Core, levels *LevelController) zapcore.Core {
+	return &levelFilterCore{Core: core, levels: levels}
+}
+
+func (c *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.levels.minLevel() && c.Core.Enabled(lvl)
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelFilterCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.enabled(entry.LoggerName, entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -5,9 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/shared/requestctx"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -22,7 +28,17 @@ type Logger interface {
 	Error(msg string, fields ...Field)
 	Fatal(msg string, fields ...Field)
 	With(fields ...Field) Logger
+	// WithLazy is like With but evaluates fields on the first log call, for
+	// values that are only known later in the request such as the route
+	WithLazy(fields ...Field) Logger
 	WithContext(ctx context.Context) Logger
+	// Named adds a dot-separated name segment; module level overrides match
+	// on these names
+	Named(name string) Logger
+	// OncePer returns a logger that writes only if nothing was logged for
+	// key within interval, and drops everything otherwise. Use it for
+	// repetitive warnings on hot paths; keys should be a small fixed set.
+	OncePer(key string, interval time.Duration) Logger
 	Sync() error
 }
 
@@ -30,6 +46,7 @@ var (
 	String   = zap.String
 	Int      = zap.Int
 	Int64    = zap.Int64
+	Uint64   = zap.Uint64
 	Float64  = zap.Float64
 	Bool     = zap.Bool
 	Duration = zap.Duration
@@ -37,10 +54,15 @@ var (
 	Error    = zap.Error
 	Any      = zap.Any
 	Stack    = zap.Stack
+	Stringer = zap.Stringer
 )
 
 type zapLogger struct {
 	*zap.Logger
+	levels *LevelController
+	once   *onceRegistry
+	// redactor masks this logger's fields; nil when redaction is off
+	redactor *Redactor
 }
 
 type LoggerConfig struct {
@@ -49,6 +71,13 @@ type LoggerConfig struct {
 	EnableCaller     bool
 	EnableStacktrace bool
 	FileConfig       *FileConfig
+	// Modules maps logger names to level overrides, e.g. middlewares: debug
+	Modules  map[string]string
+	Sampling *SamplingConfig
+	// Redaction masks sensitive keys and values in every core; nil
+	// disables it
+	Redaction *RedactionConfig
+	Sinks     []SinkConfig
 }
 
 type FileConfig struct {
@@ -59,6 +88,20 @@ type FileConfig struct {
 	MaxAge        int // Days
 	Compress      bool
 	SeparateFiles bool
+	// Async moves file writes off the logging goroutine; nil writes synchronously
+	Async *AsyncConfig
+	// Routes replaces the app.log/separate files layout when set
+	Routes []FileRoute
+}
+
+// FileRoute sends entries within a level range, optionally from one named
+// logger, to a file. Empty levels leave the range open on that side.
+type FileRoute struct {
+	File     string
+	MinLevel string
+	MaxLevel string
+	Format   string // json or console
+	Logger   string // Only entries from this logger and its children
 }
 
 // New creates a new logger with the given configuration
@@ -67,12 +110,17 @@ func New(cfg LoggerConfig) (Logger, error) {
 		return nil, err
 	}
 
-	level, err := zapcore.ParseLevel(cfg.Level)
+	if _, err := zapcore.ParseLevel(cfg.Level); err != nil {
+		cfg.Level = zapcore.InfoLevel.String()
+	}
+	levels, err := NewLevelController(cfg.Level, cfg.Modules)
 	if err != nil {
-		level = zapcore.InfoLevel
+		return nil, err
 	}
 
-	// Build cores
+	// Cores accept every level; the level controller filters in front of
+	// them so levels can change at runtime
+	level := zapcore.DebugLevel
 	cores := []zapcore.Core{
 		buildConsoleCore(cfg.Format, level),
 	}
@@ -82,15 +130,45 @@ func New(cfg LoggerConfig) (Logger, error) {
 			return nil, fmt.Errorf("failed to create log directory: %w", err)
 		}
 
-		fileCores := buildFileCores(cfg.FileConfig, level)
+		fileCores, err := buildFileCores(cfg.FileConfig)
+		if err != nil {
+			return nil, err
+		}
 		cores = append(cores, fileCores...)
 	}
 
+	if len(cfg.Sinks) > 0 {
+		sinkCores, err := buildSinkCores(cfg.Sinks)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, sinkCores...)
+	}
+
 	core := zapcore.NewTee(cores...)
+	var redactor *Redactor
+	if cfg.Redaction != nil {
+		if redactor, err = NewRedactor(*cfg.Redaction); err != nil {
+			return nil, err
+		}
+		core = newRedactingCore(core, redactor)
+	}
+	if cfg.Sampling != nil {
+		if core, err = buildSamplingCore(core, cfg.Sampling); err != nil {
+			return nil, err
+		}
+	}
+	core = newLevelFilterCore(core, levels)
 	options := buildOptions(cfg)
 	zapLog := zap.New(core, options...)
 
-	return &zapLogger{Logger: zapLog}, nil
+	return &zapLogger{Logger: zapLog, levels: levels, once: &onceRegistry{}, redactor: redactor}, nil
+}
+
+// NewFromZap adapts an existing zap logger, e.g. one built on custom cores.
+// It has no level controller, so Levels returns nil for it.
+func NewFromZap(z *zap.Logger) Logger {
+	return &zapLogger{Logger: z, once: &onceRegistry{}}
 }
 
 // Must creates a logger and panics on error
@@ -119,6 +197,54 @@ func NewWithConfig(cfg config.LoggingConfig) (Logger, error) {
 		Format:           cfg.Format,
 		EnableCaller:     cfg.EnableCaller,
 		EnableStacktrace: cfg.EnableStacktrace,
+		Modules:          cfg.Modules,
+	}
+
+	if cfg.Redaction.Enabled {
+		loggerConfig.Redaction = &RedactionConfig{
+			Keys:           cfg.Redaction.Keys,
+			Patterns:       cfg.Redaction.Patterns,
+			CustomPatterns: cfg.Redaction.CustomPatterns,
+		}
+	}
+
+	for _, sink := range cfg.Sinks {
+		if !sink.Enabled {
+			continue
+		}
+		loggerConfig.Sinks = append(loggerConfig.Sinks, SinkConfig{
+			Name:          sink.Name,
+			Type:          sink.Type,
+			Level:         sink.Level,
+			Format:        sink.Format,
+			BufferSize:    sink.BufferSize,
+			Network:       sink.Network,
+			Address:       sink.Address,
+			Tag:           sink.Tag,
+			Facility:      sink.Facility,
+			URL:           sink.URL,
+			Protocol:      sink.Protocol,
+			Headers:       sink.Headers,
+			Labels:        sink.Labels,
+			BatchSize:     sink.BatchSize,
+			FlushInterval: time.Duration(sink.FlushInterval) * time.Millisecond,
+			Timeout:       time.Duration(sink.Timeout) * time.Second,
+			MaxRetries:    sink.MaxRetries,
+		})
+	}
+
+	if cfg.Sampling.Enabled {
+		loggerConfig.Sampling = &SamplingConfig{
+			Tick:       time.Duration(cfg.Sampling.Tick) * time.Second,
+			Initial:    cfg.Sampling.Initial,
+			Thereafter: cfg.Sampling.Thereafter,
+		}
+		if len(cfg.Sampling.Levels) > 0 {
+			loggerConfig.Sampling.Levels = make(map[string]SamplingRule, len(cfg.Sampling.Levels))
+			for level, rule := range cfg.Sampling.Levels {
+				loggerConfig.Sampling.Levels[level] = SamplingRule{Initial: rule.Initial, Thereafter: rule.Thereafter}
+			}
+		}
 	}
 
 	if cfg.File.Enabled {
@@ -131,9 +257,43 @@ func NewWithConfig(cfg config.LoggingConfig) (Logger, error) {
 			Compress:      cfg.File.Compress,
 			SeparateFiles: cfg.File.SeparateFiles,
 		}
+
+		for _, route := range cfg.File.Routes {
+			loggerConfig.FileConfig.Routes = append(loggerConfig.FileConfig.Routes, FileRoute{
+				File:     route.File,
+				MinLevel: route.MinLevel,
+				MaxLevel: route.MaxLevel,
+				Format:   route.Format,
+				Logger:   route.Logger,
+			})
+		}
+
+		if cfg.File.Async.Enabled {
+			overflow, err := ParseOverflowPolicy(cfg.File.Async.Overflow)
+			if err != nil {
+				return nil, err
+			}
+			loggerConfig.FileConfig.Async = &AsyncConfig{
+				BufferSize:    cfg.File.Async.BufferSize,
+				FlushInterval: time.Duration(cfg.File.Async.FlushInterval) * time.Millisecond,
+				Overflow:      overflow,
+			}
+		}
 	}
 
-	return New(loggerConfig)
+	logger, err := New(loggerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the application logger sets the rules used by RedactQuery and
+	// friends, so fallback and ad hoc loggers cannot switch them off
+	redactor := logger.(*zapLogger).redactor
+	if redactor == nil {
+		redactor = noopRedactor
+	}
+	activeRedactor.Store(redactor)
+	return logger, nil
 }
 
 // MustWithConfig creates a logger from application config and panics on error
@@ -161,63 +321,167 @@ func buildConsoleCore(format string, level zapcore.Level) zapcore.Core {
 }
 
 // buildFileCores creates file output cores based on configuration
-func buildFileCores(cfg *FileConfig, level zapcore.Level) []zapcore.Core {
-	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
-
-	if !cfg.SeparateFiles {
-		// Single file for all logs
-		writer := &lumberjack.Logger{
-			Filename:   filepath.Join(cfg.Directory, "app.log"),
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
+func buildFileCores(cfg *FileConfig) ([]zapcore.Core, error) {
+	routes := cfg.Routes
+	if len(routes) == 0 {
+		routes = defaultFileRoutes(cfg.SeparateFiles)
+	}
+
+	parsed := make([]fileRoute, 0, len(routes))
+	for _, route := range routes {
+		r, err := parseFileRoute(route)
+		if err != nil {
+			return nil, err
 		}
-		return []zapcore.Core{
-			zapcore.NewCore(encoder, zapcore.AddSync(writer), level),
+		parsed = append(parsed, r)
+	}
+	captureSevereLevels(parsed)
+
+	cores := make([]zapcore.Core, 0, len(parsed))
+	for _, route := range parsed {
+		var core zapcore.Core = zapcore.NewCore(
+			fileEncoder(route.format),
+			fileWriteSyncer(cfg, route.file),
+			route,
+		)
+		if route.logger != "" {
+			core = &loggerNameFilterCore{Core: core, name: route.logger}
 		}
+		cores = append(cores, core)
 	}
+	return cores, nil
+}
+
+// defaultFileRoutes reproduces the classic layout: one app.log, or a file
+// per level where error.log also receives dpanic, panic and fatal entries
+func defaultFileRoutes(separateFiles bool) []FileRoute {
+	if !separateFiles {
+		return []FileRoute{{File: "app.log"}}
+	}
+	return []FileRoute{
+		{File: "error.log", MinLevel: "error"},
+		{File: "warning.log", MinLevel: "warn", MaxLevel: "warn"},
+		{File: "info.log", MinLevel: "info", MaxLevel: "info"},
+		{File: "debug.log", MinLevel: "debug", MaxLevel: "debug"},
+	}
+}
+
+// fileRoute is a parsed FileRoute; it is the level enabler of its core
+type fileRoute struct {
+	file     string
+	format   string
+	logger   string
+	min, max zapcore.Level
+	// extra holds severe levels outside [min, max] routed here so they are
+	// never lost
+	extra map[zapcore.Level]bool
+}
 
-	// Separate files for different log levels
-	var cores []zapcore.Core
-
-	// Define level-specific configurations
-	levelConfigs := []struct {
-		filename     string
-		levelEnabler zapcore.LevelEnabler
-		targetLevel  zapcore.Level
-	}{
-		{"error.log", levelEnabler(zapcore.ErrorLevel), zapcore.ErrorLevel},
-		{"warning.log", levelEnabler(zapcore.WarnLevel), zapcore.WarnLevel},
-		{"info.log", levelEnabler(zapcore.InfoLevel), zapcore.InfoLevel},
-		{"debug.log", levelEnabler(zapcore.DebugLevel), zapcore.DebugLevel},
-	}
-
-	for _, config := range levelConfigs {
-		if config.targetLevel >= level {
-			writer := &lumberjack.Logger{
-				Filename:   filepath.Join(cfg.Directory, config.filename),
-				MaxSize:    cfg.MaxSize,
-				MaxBackups: cfg.MaxBackups,
-				MaxAge:     cfg.MaxAge,
-				Compress:   cfg.Compress,
+func (r fileRoute) Enabled(lvl zapcore.Level) bool {
+	return (lvl >= r.min && lvl <= r.max) || r.extra[lvl]
+}
+
+func parseFileRoute(route FileRoute) (fileRoute, error) {
+	if route.File == "" {
+		return fileRoute{}, fmt.Errorf("file route needs a file name")
+	}
+	parsed := fileRoute{
+		file:   route.File,
+		format: route.Format,
+		logger: route.Logger,
+		min:    zapcore.DebugLevel,
+		max:    zapcore.FatalLevel,
+	}
+	if route.MinLevel != "" {
+		lvl, err := zapcore.ParseLevel(route.MinLevel)
+		if err != nil {
+			return fileRoute{}, fmt.Errorf("file route %s: invalid min level %q: %w", route.File, route.MinLevel, err)
+		}
+		parsed.min = lvl
+	}
+	if route.MaxLevel != "" {
+		lvl, err := zapcore.ParseLevel(route.MaxLevel)
+		if err != nil {
+			return fileRoute{}, fmt.Errorf("file route %s: invalid max level %q: %w", route.File, route.MaxLevel, err)
+		}
+		parsed.max = lvl
+	}
+	if parsed.max < parsed.min {
+		return fileRoute{}, fmt.Errorf("file route %s: max level %s is below min level %s", route.File, parsed.max, parsed.min)
+	}
+	return parsed, nil
+}
+
+// captureSevereLevels routes dpanic, panic and fatal entries that no
+// unfiltered route accepts to every unfiltered route, so crashes always
+// reach the log files
+func captureSevereLevels(routes []fileRoute) {
+	for _, lvl := range []zapcore.Level{zapcore.DPanicLevel, zapcore.PanicLevel, zapcore.FatalLevel} {
+		covered := false
+		for _, route := range routes {
+			if route.logger == "" && route.Enabled(lvl) {
+				covered = true
+				break
 			}
-			cores = append(cores, zapcore.NewCore(
-				encoder,
-				zapcore.AddSync(writer),
-				config.levelEnabler,
-			))
+		}
+		if covered {
+			continue
+		}
+		for i := range routes {
+			if routes[i].logger != "" {
+				continue
+			}
+			if routes[i].extra == nil {
+				routes[i].extra = make(map[zapcore.Level]bool)
+			}
+			routes[i].extra[lvl] = true
 		}
 	}
+}
 
-	return cores
+// fileEncoder returns a JSON encoder, or an uncoloured console encoder
+func fileEncoder(format string) zapcore.Encoder {
+	if format == "console" {
+		config := zap.NewProductionEncoderConfig()
+		config.EncodeTime = zapcore.ISO8601TimeEncoder
+		config.EncodeLevel = zapcore.CapitalLevelEncoder
+		return zapcore.NewConsoleEncoder(config)
+	}
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
 }
 
-// levelEnabler creates a level enabler for a specific level only
-func levelEnabler(targetLevel zapcore.Level) zapcore.LevelEnabler {
-	return zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl == targetLevel
-	})
+// loggerNameFilterCore only accepts entries from a named logger and its children
+type loggerNameFilterCore struct {
+	zapcore.Core
+	name string
+}
+
+func (c *loggerNameFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &loggerNameFilterCore{Core: c.Core.With(fields), name: c.name}
+}
+
+func (c *loggerNameFilterCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.LoggerName != c.name && !strings.HasPrefix(entry.LoggerName, c.name+".") {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// fileWriteSyncer creates the rotating writer for a log file, behind an
+// async writer when configured
+func fileWriteSyncer(cfg *FileConfig, filename string) zapcore.WriteSyncer {
+	path := filepath.Join(cfg.Directory, filename)
+	writer := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+	}
+	if cfg.Async == nil {
+		return zapcore.AddSync(writer)
+	}
+	return NewAsyncWriter(path, writer, *cfg.Async)
 }
 
 // buildOptions creates zap options based on configuration
@@ -288,41 +552,100 @@ func (l *zapLogger) Fatal(msg string, fields ...Field) {
 }
 
 func (l *zapLogger) With(fields ...Field) Logger {
-	return &zapLogger{Logger: l.Logger.With(fields...)}
+	return l.derive(l.Logger.With(fields...))
+}
+
+func (l *zapLogger) WithLazy(fields ...Field) Logger {
+	return l.derive(l.Logger.WithLazy(fields...))
+}
+
+func (l *zapLogger) Named(name string) Logger {
+	return l.derive(l.Logger.Named(name))
 }
 
+func (l *zapLogger) OncePer(key string, interval time.Duration) Logger {
+	if l.once.allow(key, interval) {
+		return l
+	}
+	return l.derive(l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &dedupCore{Core: core, levels: l.levels}
+	})))
+}
+
+// derive wraps a child zap logger, keeping the shared level and
+// deduplication state
+func (l *zapLogger) derive(child *zap.Logger) *zapLogger {
+	return &zapLogger{Logger: child, levels: l.levels, once: l.once, redactor: l.redactor}
+}
+
+// WithContext adds the trace and span IDs of the active span in ctx
 func (l *zapLogger) WithContext(ctx context.Context) Logger {
-	return &zapLogger{Logger: l.Logger.With(zap.String("trace_id", getTraceID(ctx)))}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return l
+	}
+	return l.derive(l.Logger.With(
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	))
 }
 
 func (l *zapLogger) Sync() error {
 	return l.Logger.Sync()
 }
 
+// Levels returns the level controller behind a logger created by this
+// package, or nil for other implementations
+func Levels(logger Logger) *LevelController {
+	if zl, ok := logger.(*zapLogger); ok {
+		return zl.levels
+	}
+	return nil
+}
+
 // Context utilities
-type ContextKey string
+type contextKey string
 
-const LoggerContextKey ContextKey = "logger"
+const loggerContextKey contextKey = "logger"
+
+var (
+	defaultLogger     atomic.Pointer[Logger]
+	defaultLoggerOnce sync.Once
+)
 
-// FromContext retrieves a logger from context or returns a default one
+// SetDefault sets the process-wide logger returned by FromContext when the
+// context carries none. It should be called once the configured logger exists.
+func SetDefault(logger Logger) {
+	defaultLogger.Store(&logger)
+}
+
+// Default returns the process-wide logger, falling back to a JSON info
+// logger created once if SetDefault has not been called
+func Default() Logger {
+	if logger := defaultLogger.Load(); logger != nil {
+		return *logger
+	}
+	defaultLoggerOnce.Do(func() {
+		fallback := NewSimple("info", "json")
+		defaultLogger.CompareAndSwap(nil, &fallback)
+	})
+	return *defaultLogger.Load()
+}
+
+// FromContext retrieves a logger from context or returns the default one.
+// The request's correlation ID, when present, is attached to the returned logger.
 func FromContext(ctx context.Context) Logger {
-	if logger, ok := ctx.Value(LoggerContextKey).(Logger); ok {
-		return logger
+	logger, ok := ctx.Value(loggerContextKey).(Logger)
+	if !ok {
+		logger = Default()
 	}
-	return NewSimple("info", "json")
+	if correlationID := requestctx.CorrelationID(ctx); correlationID != "" {
+		logger = logger.With(String("correlation_id", correlationID))
+	}
+	return logger
 }
 
 // WithContext adds a logger to context
 func WithContext(ctx context.Context, logger Logger) context.Context {
-	return context.WithValue(ctx, LoggerContextKey, logger)
-}
-
-// getTraceID extracts trace ID from context
-func getTraceID(ctx context.Context) string {
-	if traceID := ctx.Value("trace_id"); traceID != nil {
-		if id, ok := traceID.(string); ok {
-			return id
-		}
-	}
-	return "unknown"
+	return context.WithValue(ctx, loggerContextKey, logger)
 }
@@ -0,0 +1,429 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedValue replaces masked values in log output
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys are matched case-insensitively as substrings of field,
+// query parameter and header names
+var DefaultRedactedKeys = []string{"password", "token", "authorization", "secret", "cookie"}
+
+// builtinPatterns are the value patterns that can be enabled by name
+var builtinPatterns = map[string]*regexp.Regexp{
+	"jwt":   regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	"email": regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	"card":  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+}
+
+// DefaultRedactionPatterns enables every built-in value pattern
+var DefaultRedactionPatterns = []string{"jwt", "email", "card"}
+
+type RedactionConfig struct {
+	// Keys are denylisted name fragments; nil uses DefaultRedactedKeys
+	Keys []string
+	// Patterns names built-in value patterns (jwt, email, card); nil uses
+	// DefaultRedactionPatterns
+	Patterns []string
+	// CustomPatterns are additional regular expressions masked in values
+	CustomPatterns []string
+}
+
+// Redactor masks sensitive keys and values
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	card     *regexp.Regexp
+}
+
+// NewRedactor compiles a redactor from cfg
+func NewRedactor(cfg RedactionConfig) (*Redactor, error) {
+	keys := cfg.Keys
+	if keys == nil {
+		keys = DefaultRedactedKeys
+	}
+	patternNames := cfg.Patterns
+	if patternNames == nil {
+		patternNames = DefaultRedactionPatterns
+	}
+
+	r := &Redactor{}
+	for _, key := range keys {
+		if key = normalizeRedactionKey(key); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+	for _, name := range patternNames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		pattern, ok := builtinPatterns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction pattern %q", name)
+		}
+		if name == "card" {
+			// Card numbers are Luhn-checked to spare IDs and timestamps
+			r.card = pattern
+			continue
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+	for _, expr := range cfg.CustomPatterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+	return r, nil
+}
+
+// IsSensitiveKey reports whether values under key must be masked
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	key = normalizeRedactionKey(key)
+	for _, denied := range r.keys {
+		if strings.Contains(key, denied) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks pattern matches inside s
+func (r *Redactor) String(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, RedactedValue)
+	}
+	if r.card != nil {
+		s = r.card.ReplaceAllStringFunc(s, func(match string) string {
+			if luhnValid(match) {
+				return RedactedValue
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// Query masks denylisted parameters and pattern matches in a raw query
+// string, keeping parameter order
+func (r *Redactor) Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		name, value, found := strings.Cut(param, "=")
+		decodedName, err := url.QueryUnescape(name)
+		if err != nil {
+			decodedName = name
+		}
+		if r.IsSensitiveKey(decodedName) {
+			params[i] = name + "=" + RedactedValue
+			continue
+		}
+		if !found {
+			continue
+		}
+		decodedValue, err := url.QueryUnescape(value)
+		if err != nil {
+			decodedValue = value
+		}
+		if masked := r.String(decodedValue); masked != decodedValue {
+			params[i] = name + "=" + masked
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// Body masks a captured request or response body. JSON documents have
+// sensitive keys masked at any depth; form bodies are treated like queries.
+func (r *Redactor) Body(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return r.Query(string(body))
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err == nil {
+			if masked, err := json.Marshal(r.value("", document)); err == nil {
+				return string(masked)
+			}
+		}
+		// Truncated or invalid JSON still gets its sensitive members masked
+		return r.String(jsonMember.ReplaceAllStringFunc(string(body), func(member string) string {
+			parts := jsonMember.FindStringSubmatch(member)
+			if !r.IsSensitiveKey(parts[1]) {
+				return member
+			}
+			return member[:len(member)-len(parts[2])] + `"` + RedactedValue + `"`
+		}))
+	}
+	return r.String(string(body))
+}
+
+// jsonMember matches a "key": value pair, including a string value cut off
+// at the end of a truncated body
+var jsonMember = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"\s*:\s*("(?:[^"\\]|\\.)*"?|[^,{}\[\]\s"]+)`)
+
+// Headers returns the headers as a flat map with sensitive values masked
+func (r *Redactor) Headers(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if r.IsSensitiveKey(name) {
+			redacted[name] = RedactedValue
+			continue
+		}
+		redacted[name] = r.String(strings.Join(values, ", "))
+	}
+	return redacted
+}
+
+// Fields masks a copy of fields
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.field(field)
+	}
+	return redacted
+}
+
+func (r *Redactor) field(field zapcore.Field) zapcore.Field {
+	if r.IsSensitiveKey(field.Key) && field.Type != zapcore.SkipType {
+		return zap.String(field.Key, RedactedValue)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		if masked := r.String(field.String); masked != field.String {
+			return zap.String(field.Key, masked)
+		}
+	case zapcore.ByteStringType:
+		if masked := r.String(string(field.Interface.([]byte))); masked != string(field.Interface.([]byte)) {
+			return zap.String(field.Key, masked)
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			if masked := r.String(err.Error()); masked != err.Error() {
+				return zap.String(field.Key, masked)
+			}
+		}
+	case zapcore.StringerType:
+		if stringer, ok := field.Interface.(fmt.Stringer); ok {
+			if _, sensitive := stringer.(SensitiveValue); sensitive {
+				return field
+			}
+			return zap.String(field.Key, r.String(stringer.String()))
+		}
+	case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.InlineMarshalerType:
+		return r.structured(field)
+	}
+	return field
+}
+
+// structured encodes nested values to their generic form so keys and
+// strings at any depth can be masked
+func (r *Redactor) structured(field zapcore.Field) zapcore.Field {
+	var value interface{}
+
+	switch field.Type {
+	case zapcore.ReflectType:
+		if field.Interface == nil {
+			return field
+		}
+		payload, err := json.Marshal(field.Interface)
+		if err != nil {
+			return field
+		}
+		// Numbers are kept as json.Number so int64 values beyond 2^53 are
+		// written back without losing precision
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return field
+		}
+	default:
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		if field.Type == zapcore.InlineMarshalerType {
+			inline := make([]zapcore.Field, 0, len(enc.Fields))
+			for key, nested := range enc.Fields {
+				inline = append(inline, zap.Any(key, r.value(key, nested)))
+			}
+			return zap.Inline(fieldList(inline))
+		}
+		value = enc.Fields[field.Key]
+	}
+
+	return zap.Any(field.Key, r.value(field.Key, value))
+}
+
+// Value masks a decoded JSON-like value found under key, recursing into
+// maps and slices
+func (r *Redactor) Value(key string, value interface{}) interface{} {
+	return r.value(key, value)
+}
+
+func (r *Redactor) value(key string, value interface{}) interface{} {
+	if r.IsSensitiveKey(key) {
+		return RedactedValue
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for nestedKey, nested := range v {
+			redacted[nestedKey] = r.value(nestedKey, nested)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, nested := range v {
+			redacted[i] = r.value("", nested)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// fieldList marshals already redacted fields inline
+type fieldList []zapcore.Field
+
+func (f fieldList) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range f {
+		field.AddTo(enc)
+	}
+	return nil
+}
+
+func normalizeRedactionKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(key)
+}
+
+func luhnValid(number string) bool {
+	sum, digits, double := 0, 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// SensitiveValue always renders masked, whether logged, printed or
+// marshalled, so structs can carry secrets without leaking them
+type SensitiveValue string
+
+func (SensitiveValue) String() string   { return RedactedValue }
+func (SensitiveValue) GoString() string { return RedactedValue }
+
+func (SensitiveValue) MarshalText() ([]byte, error) {
+	return []byte(RedactedValue), nil
+}
+
+// Reveal returns the underlying value
+func (s SensitiveValue) Reveal() string {
+	return string(s)
+}
+
+// Sensitive creates a field whose value is never written
+func Sensitive(key string, value string) Field {
+	return zap.Stringer(key, SensitiveValue(value))
+}
+
+var (
+	activeRedactor atomic.Pointer[Redactor]
+	// noopRedactor leaves everything as is when redaction is disabled
+	noopRedactor = &Redactor{}
+)
+
+func init() {
+	redactor, _ := NewRedactor(RedactionConfig{})
+	activeRedactor.Store(redactor)
+}
+
+// RedactQuery masks a raw query string with the rules of the application
+// logger built by NewWithConfig
+func RedactQuery(rawQuery string) string {
+	return activeRedactor.Load().Query(rawQuery)
+}
+
+// RedactHeaders masks header values with the rules of the application
+// logger built by NewWithConfig
+func RedactHeaders(header http.Header) map[string]string {
+	return activeRedactor.Load().Headers(header)
+}
+
+// RedactHeader masks a single header value with the rules of the
+// application logger built by NewWithConfig
+func RedactHeader(name, value string) string {
+	redactor := activeRedactor.Load()
+	if redactor.IsSensitiveKey(name) {
+		return RedactedValue
+	}
+	return redactor.String(value)
+}
+
+// RedactBody masks a captured body with the rules of the application
+// logger built by NewWithConfig
+func RedactBody(contentType string, body []byte) string {
+	return activeRedactor.Load().Body(contentType, body)
+}
+
+// redactingCore masks fields and messages once, before they fan out to the
+// output cores
+type redactingCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+func newRedactingCore(core zapcore.Core, redactor *Redactor) zapcore.Core {
+	return &redactingCore{Core: core, redactor: redactor}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactor.Fields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write hands the redacted entry to the wrapped cores that accept it, so
+// level-specific file cores keep their own filtering
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.String(entry.Message)
+	if checked := c.Core.Check(entry, nil); checked != nil {
+		checked.Write(c.redactor.Fields(fields)...)
+	}
+	return nil
+}
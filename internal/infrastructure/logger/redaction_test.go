@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+func TestRedactorPreservesLargeIntegers(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	fields := redactor.Fields([]zapcore.Field{
+		zap.Any("order", map[string]int64{"id": 9007199254740993, "password": 1}),
+	})
+	buf, err := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()).EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	want := `"order":{"id":9007199254740993,"password":"[REDACTED]"}`
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Fatalf("got %s, want it to contain %s", got, want)
+	}
+}
+
+func TestAdHocLoggersKeepApplicationRedaction(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer secret"}}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{name: "redaction disabled", enabled: false, want: "Bearer secret"},
+		{name: "redaction enabled", enabled: true, want: RedactedValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := NewWithConfig(config.LoggingConfig{
+				Level:     "info",
+				Format:    "json",
+				Redaction: config.RedactionConfig{Enabled: tt.enabled},
+			})
+			if err != nil {
+				t.Fatalf("NewWithConfig: %v", err)
+			}
+			SetDefault(log)
+
+			// Neither a fallback nor an ad hoc logger may change the rules
+			_ = NewSimple("error", "console")
+			if got := Default(); got != log {
+				t.Fatal("Default did not return the logger passed to SetDefault")
+			}
+
+			if got := RedactHeaders(header)["Authorization"]; got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type SamplingConfig struct {
+	Tick       time.Duration
+	Initial    int // Entries per message logged each tick
+	Thereafter int // Then every Mth entry is logged
+	// Levels overrides Initial/Thereafter per level; a rule with Initial 0
+	// turns sampling off for that level
+	Levels map[string]SamplingRule
+}
+
+type SamplingRule struct {
+	Initial    int
+	Thereafter int
+}
+
+// Stats reports log entries dropped on purpose and per-sink delivery
+type Stats struct {
+	SampledDropped      map[string]uint64    `json:"sampled_dropped"`
+	DeduplicatedDropped uint64               `json:"deduplicated_dropped"`
+	Sinks               map[string]SinkStats `json:"sinks,omitempty"`
+	AsyncFiles          map[string]SinkStats `json:"async_files,omitempty"`
+}
+
+// unsampledLevel is the lowest level that is never sampled; errors must
+// always reach the sinks
+const unsampledLevel = zapcore.ErrorLevel
+
+var (
+	sampledDropped      [unsampledLevel - zapcore.DebugLevel]atomic.Uint64
+	deduplicatedDropped atomic.Uint64
+)
+
+// Metrics returns the process-wide counters of dropped log entries
+func Metrics() Stats {
+	stats := Stats{
+		SampledDropped:      make(map[string]uint64, len(sampledDropped)),
+		DeduplicatedDropped: deduplicatedDropped.Load(),
+		Sinks:               sinkStats(),
+		AsyncFiles:          asyncWriterStats(),
+	}
+	for i := range sampledDropped {
+		stats.SampledDropped[(zapcore.DebugLevel + zapcore.Level(i)).String()] = sampledDropped[i].Load()
+	}
+	return stats
+}
+
+func countSamplingDecision(entry zapcore.Entry, decision zapcore.SamplingDecision) {
+	if decision&zapcore.LogDropped != 0 && entry.Level < unsampledLevel {
+		sampledDropped[entry.Level-zapcore.DebugLevel].Add(1)
+	}
+}
+
+// buildSamplingCore samples debug through warn entries by level and message.
+// Each level gets its own sampler so rules can differ; errors and above pass
+// through unsampled.
+func buildSamplingCore(core zapcore.Core, cfg *SamplingConfig) (zapcore.Core, error) {
+	tick := cfg.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	rules := make(map[zapcore.Level]SamplingRule)
+	for lvl := zapcore.DebugLevel; lvl < unsampledLevel; lvl++ {
+		rules[lvl] = SamplingRule{Initial: cfg.Initial, Thereafter: cfg.Thereafter}
+	}
+	for name, rule := range cfg.Levels {
+		lvl, err := zapcore.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling level %q: %w", name, err)
+		}
+		if lvl >= unsampledLevel {
+			return nil, fmt.Errorf("sampling is not supported for level %s", lvl)
+		}
+		rules[lvl] = rule
+	}
+
+	samplers := make(map[zapcore.Level]zapcore.Core, len(rules))
+	for lvl, rule := range rules {
+		if rule.Initial <= 0 {
+			continue
+		}
+		samplers[lvl] = zapcore.NewSamplerWithOptions(core, tick, rule.Initial, rule.Thereafter,
+			zapcore.SamplerHook(countSamplingDecision))
+	}
+	if len(samplers) == 0 {
+		return core, nil
+	}
+	return &samplingCore{Core: core, samplers: samplers}, nil
+}
+
+// samplingCore routes each entry to the sampler for its level
+type samplingCore struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+	for lvl, sampler := range c.samplers {
+		samplers[lvl] = sampler.With(fields)
+	}
+	return &samplingCore{Core: c.Core.With(fields), samplers: samplers}
+}
+
+func (c *samplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if sampler, ok := c.samplers[entry.Level]; ok {
+		return sampler.Check(entry, checked)
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// onceRegistry remembers when each OncePer key last logged. Keys are meant
+// to be a small, fixed set chosen by the caller, so entries are not evicted.
+type onceRegistry struct {
+	last sync.Map // key -> *atomic.Int64 (unix nanoseconds)
+}
+
+// allow reports whether key has not logged within interval and, if so,
+// claims the current interval for the caller
+func (o *onceRegistry) allow(key string, interval time.Duration) bool {
+	now := time.Now().UnixNano()
+	value, _ := o.last.LoadOrStore(key, new(atomic.Int64))
+	last := value.(*atomic.Int64)
+
+	for {
+		previous := last.Load()
+		if previous != 0 && now-previous < int64(interval) {
+			return false
+		}
+		if last.CompareAndSwap(previous, now) {
+			return true
+		}
+	}
+}
+
+// dedupCore swallows entries from a logger whose OncePer key is
+// suppressed, counting those that would otherwise have been written
+type dedupCore struct {
+	zapcore.Core
+	levels *LevelController
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *dedupCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.enabled(entry) {
+		deduplicatedDropped.Add(1)
+	}
+	return checked
+}
+
+// enabled falls back to the wrapped core for loggers built by NewFromZap,
+// which have no level controller
+func (c *dedupCore) enabled(entry zapcore.Entry) bool {
+	if c.levels == nil {
+		return c.Core.Enabled(entry.Level)
+	}
+	return c.levels.enabled(entry.LoggerName, entry.Level)
+}
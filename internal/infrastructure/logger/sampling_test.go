@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestOncePerWithoutLevelController(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := NewFromZap(zap.New(core))
+
+	before := Metrics().DeduplicatedDropped
+	log.OncePer("once-per-test", time.Minute).Info("first")
+	log.OncePer("once-per-test", time.Minute).Info("second")
+	log.OncePer("once-per-test", time.Minute).Debug("below level")
+
+	if got := logs.Len(); got != 1 {
+		t.Fatalf("expected 1 entry written, got %d", got)
+	}
+	if got := Metrics().DeduplicatedDropped - before; got != 1 {
+		t.Fatalf("expected 1 deduplicated drop, got %d", got)
+	}
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// HandleLevelSignals raises the global level to level on SIGUSR1, reverting
+// after ttl when it is positive, and restores the configured levels on
+// SIGUSR2. It returns when ctx is done.
+func HandleLevelSignals(ctx context.Context, levels *LevelController, level string, ttl time.Duration, log Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			switch sig {
+			case syscall.SIGUSR1:
+				if err := levels.SetLevel(level, ttl); err != nil {
+					log.Error("Failed to change log level", String("signal", sig.String()), Error(err))
+					continue
+				}
+				log.Warn("Log level changed by signal",
+					String("signal", sig.String()),
+					String("level", level),
+					Duration("ttl", ttl),
+				)
+			case syscall.SIGUSR2:
+				levels.Reset()
+				log.Warn("Log levels reset by signal",
+					String("signal", sig.String()),
+					String("level", levels.Level().String()),
+				)
+			}
+		}
+	}
+}
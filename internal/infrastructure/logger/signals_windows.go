@@ -0,0 +1,14 @@
+//go:build windows
+
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// HandleLevelSignals is a no-op on Windows, which has no SIGUSR1/SIGUSR2;
+// use the admin endpoint instead
+func HandleLevelSignals(ctx context.Context, levels *LevelController, level string, ttl time.Duration, log Logger) {
+	<-ctx.Done()
+}
@@ -0,0 +1,361 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSinkBufferSize    = 1024
+	defaultSinkBatchSize     = 100
+	defaultSinkFlushInterval = time.Second
+	defaultSinkTimeout       = 5 * time.Second
+	defaultSinkMaxRetries    = 3
+	sinkSyncTimeout          = 5 * time.Second
+)
+
+// SinkConfig configures an additional log destination. Entries are queued
+// in a bounded buffer and shipped by a background worker; when the buffer
+// is full new entries are dropped rather than blocking the caller.
+type SinkConfig struct {
+	Name       string
+	Type       string // syslog, http or otlp
+	Level      string // Minimum level, on top of the logger's own level
+	Format     string // json or console, for syslog and http
+	BufferSize int
+
+	// syslog
+	Network  string // udp, tcp or unix
+	Address  string
+	Tag      string
+	Facility string
+
+	// http and otlp
+	URL           string
+	Protocol      string // http only: json or loki
+	Headers       map[string]string
+	Labels        map[string]string // Loki stream labels or OTLP resource attributes
+	BatchSize     int
+	FlushInterval time.Duration
+	Timeout       time.Duration
+	MaxRetries    int
+}
+
+// SinkStats counts what happened to the entries handed to a sink
+type SinkStats struct {
+	Sent    uint64 `json:"sent"`
+	Dropped uint64 `json:"dropped"` // Buffer full
+	Failed  uint64 `json:"failed"`  // Export failed after retries
+}
+
+// sinkRecord is a queued entry. line holds the encoded entry; attributes are
+// only filled for sinks that ship structured data.
+type sinkRecord struct {
+	entry      zapcore.Entry
+	line       []byte
+	attributes map[string]interface{}
+}
+
+// sinkExporter ships records to a destination. export is only called from
+// the sink's worker goroutine.
+type sinkExporter interface {
+	export(records []sinkRecord) error
+	close() error
+}
+
+// sinks tracks every sink by name for Metrics
+var sinks sync.Map // name -> *sinkQueue
+
+func sinkStats() map[string]SinkStats {
+	stats := make(map[string]SinkStats)
+	sinks.Range(func(key, value interface{}) bool {
+		queue := value.(*sinkQueue)
+		stats[key.(string)] = SinkStats{
+			Sent:    queue.sent.Load(),
+			Dropped: queue.dropped.Load(),
+			Failed:  queue.failed.Load(),
+		}
+		return true
+	})
+	return stats
+}
+
+// buildSinkCores creates a core per configured sink
+func buildSinkCores(configs []SinkConfig) ([]zapcore.Core, error) {
+	cores := make([]zapcore.Core, 0, len(configs))
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("%s-%d", cfg.Type, i)
+		}
+		core, err := buildSinkCore(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("log sink %s: %w", cfg.Name, err)
+		}
+		cores = append(cores, core)
+	}
+	return cores, nil
+}
+
+func buildSinkCore(cfg SinkConfig) (zapcore.Core, error) {
+	level := zapcore.DebugLevel
+	if cfg.Level != "" {
+		parsed, err := zapcore.ParseLevel(cfg.Level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level %q: %w", cfg.Level, err)
+		}
+		level = parsed
+	}
+
+	var (
+		exporter   sinkExporter
+		structured bool
+		batchSize  = 1
+		err        error
+	)
+	switch strings.ToLower(cfg.Type) {
+	case "syslog":
+		exporter, err = newSyslogExporter(cfg)
+	case "http":
+		exporter, err = newHTTPExporter(cfg)
+		batchSize = cfg.BatchSize
+	case "otlp":
+		exporter, err = newOTLPExporter(cfg)
+		batchSize, structured = cfg.BatchSize, true
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		batchSize = defaultSinkBatchSize
+	}
+
+	queue := newSinkQueue(exporter, cfg.BufferSize, batchSize, cfg.FlushInterval)
+	if previous, loaded := sinks.Swap(cfg.Name, queue); loaded {
+		// A rebuilt logger replaces the sink of the same name
+		previous.(*sinkQueue).stop()
+	}
+
+	return &sinkCore{
+		LevelEnabler: level,
+		encoder:      newSinkEncoder(cfg.Format),
+		queue:        queue,
+		structured:   structured,
+	}, nil
+}
+
+func newSinkEncoder(format string) zapcore.Encoder {
+	config := zap.NewProductionEncoderConfig()
+	config.EncodeTime = zapcore.ISO8601TimeEncoder
+	if format == "console" {
+		return zapcore.NewConsoleEncoder(config)
+	}
+	return zapcore.NewJSONEncoder(config)
+}
+
+// sinkCore encodes entries and hands them to the sink's queue
+type sinkCore struct {
+	zapcore.LevelEnabler
+	encoder    zapcore.Encoder
+	queue      *sinkQueue
+	structured bool
+	context    []zapcore.Field
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &sinkCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      c.encoder.Clone(),
+		queue:        c.queue,
+		structured:   c.structured,
+		context:      c.context,
+	}
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	if c.structured {
+		clone.context = append(append([]zapcore.Field(nil), c.context...), fields...)
+	}
+	return clone
+}
+
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	record := sinkRecord{
+		entry: entry,
+		line:  []byte(strings.TrimSuffix(buf.String(), "\n")),
+	}
+	buf.Free()
+
+	if c.structured {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, field := range c.context {
+			field.AddTo(enc)
+		}
+		for _, field := range fields {
+			field.AddTo(enc)
+		}
+		record.attributes = enc.Fields
+	}
+
+	c.queue.enqueue(record)
+	if entry.Level > zapcore.ErrorLevel {
+		// The process may be about to exit
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.queue.flush(sinkSyncTimeout)
+}
+
+// sinkQueue is the bounded buffer between callers and a sink's exporter
+type sinkQueue struct {
+	exporter      sinkExporter
+	records       chan sinkRecord
+	flushRequests chan chan error
+	done          chan struct{}
+	stopOnce      sync.Once
+	batchSize     int
+	flushInterval time.Duration
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+func newSinkQueue(exporter sinkExporter, bufferSize, batchSize int, flushInterval time.Duration) *sinkQueue {
+	if bufferSize <= 0 {
+		bufferSize = defaultSinkBufferSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultSinkFlushInterval
+	}
+
+	q := &sinkQueue{
+		exporter:      exporter,
+		records:       make(chan sinkRecord, bufferSize),
+		flushRequests: make(chan chan error),
+		done:          make(chan struct{}),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+	go q.run()
+	return q
+}
+
+func (q *sinkQueue) enqueue(record sinkRecord) {
+	select {
+	case q.records <- record:
+	default:
+		q.dropped.Add(1)
+	}
+}
+
+// flush waits until everything queued so far has been exported
+func (q *sinkQueue) flush(timeout time.Duration) error {
+	result := make(chan error, 1)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case q.flushRequests <- result:
+	case <-q.done:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("timed out flushing log sink")
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("timed out flushing log sink")
+	}
+}
+
+func (q *sinkQueue) stop() {
+	q.stopOnce.Do(func() {
+		close(q.done)
+	})
+}
+
+func (q *sinkQueue) run() {
+	ticker := time.NewTicker(q.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]sinkRecord, 0, q.batchSize)
+	export := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := q.exporter.export(batch)
+		if err != nil {
+			q.failed.Add(uint64(len(batch)))
+		} else {
+			q.sent.Add(uint64(len(batch)))
+		}
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		select {
+		case record := <-q.records:
+			batch = append(batch, record)
+			if len(batch) >= q.batchSize {
+				_ = export()
+			}
+		case <-ticker.C:
+			_ = export()
+		case result := <-q.flushRequests:
+			var err error
+			for drained := false; !drained; {
+				select {
+				case record := <-q.records:
+					batch = append(batch, record)
+					if len(batch) >= q.batchSize {
+						err = export()
+					}
+				default:
+					drained = true
+				}
+			}
+			if exportErr := export(); exportErr != nil {
+				err = exportErr
+			}
+			result <- err
+		case <-q.done:
+			_ = export()
+			_ = q.exporter.close()
+			return
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt n, doubling from 200ms up to 5s
+func backoff(attempt int) time.Duration {
+	delay := 200 * time.Millisecond << attempt
+	if delay <= 0 || delay > 5*time.Second {
+		return 5 * time.Second
+	}
+	return delay
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpExporter pushes batches as a JSON array of entries, or as a Loki push
+// request with one stream per level
+type httpExporter struct {
+	client     *http.Client
+	url        string
+	protocol   string
+	headers    map[string]string
+	labels     map[string]string
+	maxRetries int
+}
+
+func newHTTPExporter(cfg SinkConfig) (*httpExporter, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("http sink url is required")
+	}
+	protocol := strings.ToLower(cfg.Protocol)
+	switch protocol {
+	case "":
+		protocol = "json"
+	case "json", "loki":
+	default:
+		return nil, fmt.Errorf("unsupported http sink protocol %q", cfg.Protocol)
+	}
+
+	return &httpExporter{
+		client:     newSinkHTTPClient(cfg.Timeout),
+		url:        cfg.URL,
+		protocol:   protocol,
+		headers:    cfg.Headers,
+		labels:     cfg.Labels,
+		maxRetries: sinkMaxRetries(cfg.MaxRetries),
+	}, nil
+}
+
+func (e *httpExporter) export(records []sinkRecord) error {
+	var (
+		body []byte
+		err  error
+	)
+	if e.protocol == "loki" {
+		body, err = e.lokiPayload(records)
+	} else {
+		body, err = jsonLinesPayload(records)
+	}
+	if err != nil {
+		return err
+	}
+	return postWithRetry(e.client, e.url, e.headers, body, e.maxRetries)
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// jsonLinesPayload wraps already encoded JSON entries in an array; console
+// encoded entries are sent as strings
+func jsonLinesPayload(records []sinkRecord) ([]byte, error) {
+	entries := make([]json.RawMessage, 0, len(records))
+	for _, record := range records {
+		if json.Valid(record.line) {
+			entries = append(entries, record.line)
+			continue
+		}
+		quoted, err := json.Marshal(string(record.line))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, quoted)
+	}
+	return json.Marshal(entries)
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (e *httpExporter) lokiPayload(records []sinkRecord) ([]byte, error) {
+	streams := make(map[string]*lokiStream)
+	var order []string
+	for _, record := range records {
+		level := record.entry.Level.String()
+		stream, ok := streams[level]
+		if !ok {
+			labels := make(map[string]string, len(e.labels)+1)
+			for name, value := range e.labels {
+				labels[name] = value
+			}
+			labels["level"] = level
+			stream = &lokiStream{Stream: labels}
+			streams[level] = stream
+			order = append(order, level)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(record.entry.Time.UnixNano(), 10),
+			string(record.line),
+		})
+	}
+
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, level := range order {
+		payload.Streams = append(payload.Streams, streams[level])
+	}
+	return json.Marshal(payload)
+}
+
+func newSinkHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultSinkTimeout
+	}
+	// Outbound calls from the logger must not be traced or logged themselves
+	return &http.Client{Timeout: timeout}
+}
+
+func sinkMaxRetries(configured int) int {
+	if configured < 0 {
+		return 0
+	}
+	if configured == 0 {
+		return defaultSinkMaxRetries
+	}
+	return configured
+}
+
+// postWithRetry posts a JSON body, retrying network errors, 429 and 5xx
+// responses with exponential backoff
+func postWithRetry(client *http.Client, url string, headers map[string]string, body []byte, maxRetries int) error {
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff(attempt - 1))
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("log sink responded with %s", resp.Status)
+		default:
+			return fmt.Errorf("log sink rejected batch with %s", resp.Status)
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultOTLPLogsURL = "http://localhost:4318/v1/logs"
+
+// otlpExporter sends batches to an OTLP/HTTP collector using the JSON
+// encoding of ExportLogsServiceRequest
+type otlpExporter struct {
+	client     *http.Client
+	url        string
+	headers    map[string]string
+	resource   []otlpKeyValue
+	maxRetries int
+}
+
+func newOTLPExporter(cfg SinkConfig) (*otlpExporter, error) {
+	url := cfg.URL
+	if url == "" {
+		url = defaultOTLPLogsURL
+	}
+
+	// Labels become resource attributes; service.name is required by most backends
+	attributes := map[string]string{"service.name": "go-clean-template"}
+	for name, value := range cfg.Labels {
+		attributes[name] = value
+	}
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	resource := make([]otlpKeyValue, 0, len(names))
+	for _, name := range names {
+		resource = append(resource, otlpKeyValue{Key: name, Value: otlpValue(attributes[name])})
+	}
+
+	return &otlpExporter{
+		client:     newSinkHTTPClient(cfg.Timeout),
+		url:        url,
+		headers:    cfg.Headers,
+		resource:   resource,
+		maxRetries: sinkMaxRetries(cfg.MaxRetries),
+	}, nil
+}
+
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string                 `json:"timeUnixNano"`
+	ObservedTimeUnixNano string                 `json:"observedTimeUnixNano"`
+	SeverityNumber       int                    `json:"severityNumber"`
+	SeverityText         string                 `json:"severityText"`
+	Body                 map[string]interface{} `json:"body"`
+	Attributes           []otlpKeyValue         `json:"attributes,omitempty"`
+	TraceID              string                 `json:"traceId,omitempty"`
+	SpanID               string                 `json:"spanId,omitempty"`
+}
+
+func (e *otlpExporter) export(records []sinkRecord) error {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	logRecords := make([]otlpLogRecord, 0, len(records))
+	for _, record := range records {
+		logRecords = append(logRecords, e.logRecord(record, observed))
+	}
+
+	payload := map[string]interface{}{
+		"resourceLogs": []map[string]interface{}{{
+			"resource": map[string]interface{}{"attributes": e.resource},
+			"scopeLogs": []map[string]interface{}{{
+				"scope":      map[string]string{"name": "go-clean-template/logger"},
+				"logRecords": logRecords,
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return postWithRetry(e.client, e.url, e.headers, body, e.maxRetries)
+}
+
+func (e *otlpExporter) logRecord(record sinkRecord, observed string) otlpLogRecord {
+	logRecord := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(record.entry.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       otlpSeverity(record.entry.Level),
+		SeverityText:         record.entry.Level.CapitalString(),
+		Body:                 otlpValue(record.entry.Message),
+	}
+
+	names := make([]string, 0, len(record.attributes))
+	for name := range record.attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := record.attributes[name]
+		// Trace context added by WithContext maps onto the record itself
+		switch name {
+		case "trace_id":
+			logRecord.TraceID = fmt.Sprint(value)
+			continue
+		case "span_id":
+			logRecord.SpanID = fmt.Sprint(value)
+			continue
+		}
+		logRecord.Attributes = append(logRecord.Attributes, otlpKeyValue{Key: name, Value: otlpValue(value)})
+	}
+	if record.entry.LoggerName != "" {
+		logRecord.Attributes = append(logRecord.Attributes, otlpKeyValue{Key: "logger.name", Value: otlpValue(record.entry.LoggerName)})
+	}
+	return logRecord
+}
+
+func (e *otlpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// otlpSeverity maps zap levels to OTLP severity numbers
+func otlpSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel:
+		return 18
+	default:
+		return 21
+	}
+}
+
+// otlpValue converts values produced by zapcore.MapObjectEncoder to an OTLP AnyValue
+func otlpValue(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return map[string]interface{}{"intValue": fmt.Sprint(v)}
+	case float32, float64:
+		return map[string]interface{}{"doubleValue": v}
+	case time.Time:
+		return map[string]interface{}{"stringValue": v.Format(time.RFC3339Nano)}
+	case time.Duration:
+		return map[string]interface{}{"stringValue": v.String()}
+	case []interface{}:
+		values := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpValue(item))
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		values := make([]otlpKeyValue, 0, len(v))
+		for _, name := range names {
+			values = append(values, otlpKeyValue{Key: name, Value: otlpValue(v[name])})
+		}
+		return map[string]interface{}{"kvlistValue": map[string]interface{}{"values": values}}
+	default:
+		return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+}
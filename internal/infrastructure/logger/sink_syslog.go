@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverity maps zap levels to RFC 5424 severities
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// syslogExporter writes RFC 5424 messages over UDP, TCP (octet-counted
+// framing per RFC 6587) or a unix socket, reconnecting after write errors
+type syslogExporter struct {
+	network  string
+	address  string
+	tag      string
+	facility int
+	hostname string
+	pid      string
+	timeout  time.Duration
+	conn     net.Conn
+}
+
+func newSyslogExporter(cfg SinkConfig) (*syslogExporter, error) {
+	network := strings.ToLower(cfg.Network)
+	switch network {
+	case "":
+		network = "udp"
+	case "udp", "tcp", "unix":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+
+	facility := syslogFacilities["local0"]
+	if cfg.Facility != "" {
+		f, ok := syslogFacilities[strings.ToLower(cfg.Facility)]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", cfg.Facility)
+		}
+		facility = f
+	}
+
+	tag := cfg.Tag
+	if tag == "" {
+		tag = "go-clean-template"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSinkTimeout
+	}
+
+	return &syslogExporter{
+		network:  network,
+		address:  cfg.Address,
+		tag:      tag,
+		facility: facility,
+		hostname: hostname,
+		pid:      strconv.Itoa(os.Getpid()),
+		timeout:  timeout,
+	}, nil
+}
+
+func (e *syslogExporter) export(records []sinkRecord) error {
+	var lastErr error
+	for _, record := range records {
+		if err := e.write(e.format(record)); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// format renders an RFC 5424 message with the encoded entry as MSG
+func (e *syslogExporter) format(record sinkRecord) []byte {
+	priority := e.facility*8 + syslogSeverity(record.entry.Level)
+	msgID := "-"
+	if record.entry.LoggerName != "" {
+		msgID = record.entry.LoggerName
+	}
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %s %s - %s",
+		priority,
+		record.entry.Time.UTC().Format(time.RFC3339Nano),
+		e.hostname, e.tag, e.pid, msgID, record.line,
+	))
+}
+
+// write sends one message, redialling once if the connection went away
+func (e *syslogExporter) write(message []byte) error {
+	if e.network == "tcp" {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if e.conn == nil {
+			if e.conn, err = e.dial(); err != nil {
+				continue
+			}
+		}
+		_ = e.conn.SetWriteDeadline(time.Now().Add(e.timeout))
+		if _, err = e.conn.Write(message); err == nil {
+			return nil
+		}
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+	return err
+}
+
+func (e *syslogExporter) dial() (net.Conn, error) {
+	if e.network != "unix" {
+		return net.DialTimeout(e.network, e.address, e.timeout)
+	}
+	// Local syslog daemons usually listen on a datagram socket
+	if conn, err := net.DialTimeout("unixgram", e.address, e.timeout); err == nil {
+		return conn, nil
+	}
+	return net.DialTimeout("unix", e.address, e.timeout)
+}
+
+func (e *syslogExporter) close() error {
+	if e.conn == nil {
+		return nil
+	}
+	return e.conn.Close()
+}
@@ -0,0 +1,380 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newTestSink builds a sink core and a logger writing only to it
+func newTestSink(t *testing.T, cfg SinkConfig) (*zap.Logger, *sinkCore) {
+	t.Helper()
+
+	core, err := buildSinkCore(cfg)
+	if err != nil {
+		t.Fatalf("buildSinkCore: %v", err)
+	}
+	sc := core.(*sinkCore)
+	t.Cleanup(func() {
+		sc.queue.stop()
+		sinks.Delete(cfg.Name)
+	})
+	return zap.New(sc), sc
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:    "test-syslog-udp",
+		Type:    "syslog",
+		Network: "udp",
+		Address: conn.LocalAddr().String(),
+		Tag:     "test",
+	})
+	log.Named("orders").Warn("disk almost full", zap.Int("free_mb", 12))
+	if err := core.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	message := string(buf[:n])
+
+	// local0 (16) * 8 + warning (4)
+	if !strings.HasPrefix(message, "<132>1 ") {
+		t.Errorf("unexpected priority header in %q", message)
+	}
+	for _, want := range []string{" test ", " orders ", `"msg":"disk almost full"`, `"free_mb":12`} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message %q does not contain %q", message, want)
+		}
+	}
+	if stats := sinkStats()["test-syslog-udp"]; stats.Sent != 1 || stats.Failed != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			size, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				break
+			}
+			message := make([]byte, size)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				break
+			}
+			messages = append(messages, string(message))
+		}
+		received <- messages
+	}()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:    "test-syslog-tcp",
+		Type:    "syslog",
+		Network: "tcp",
+		Address: listener.Addr().String(),
+	})
+	log.Info("first")
+	log.Error("second")
+	if err := core.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	select {
+	case messages := <-received:
+		if len(messages) != 2 {
+			t.Fatalf("expected 2 framed messages, got %d: %q", len(messages), messages)
+		}
+		if !strings.HasPrefix(messages[0], "<134>1 ") || !strings.Contains(messages[0], `"msg":"first"`) {
+			t.Errorf("unexpected first message %q", messages[0])
+		}
+		if !strings.HasPrefix(messages[1], "<131>1 ") || !strings.Contains(messages[1], `"msg":"second"`) {
+			t.Errorf("unexpected second message %q", messages[1])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for syslog messages")
+	}
+}
+
+func TestHTTPSinkBatching(t *testing.T) {
+	batches := make(chan []map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entries []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if r.Header.Get("X-Scope-OrgID") != "tenant" {
+			t.Errorf("missing configured header")
+		}
+		batches <- entries
+	}))
+	defer server.Close()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:          "test-http-batch",
+		Type:          "http",
+		URL:           server.URL,
+		Headers:       map[string]string{"X-Scope-OrgID": "tenant"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	log.Info("one")
+	log.Info("two")
+	log.Info("three")
+
+	select {
+	case batch := <-batches:
+		if len(batch) != 2 || batch[0]["msg"] != "one" || batch[1]["msg"] != "two" {
+			t.Fatalf("unexpected first batch %v", batch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("full batch was not exported")
+	}
+
+	if err := core.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if batch := <-batches; len(batch) != 1 || batch[0]["msg"] != "three" {
+		t.Fatalf("unexpected flushed batch %v", batch)
+	}
+	if stats := sinkStats()["test-http-batch"]; stats.Sent != 3 || stats.Failed != 0 || stats.Dropped != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPSinkLokiPayload(t *testing.T) {
+	var payload struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:     "test-http-loki",
+		Type:     "http",
+		Protocol: "loki",
+		URL:      server.URL,
+		Labels:   map[string]string{"app": "api"},
+	})
+	log.Info("a")
+	log.Warn("b")
+	log.Info("c")
+	if err := core.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if len(payload.Streams) != 2 {
+		t.Fatalf("expected one stream per level, got %d", len(payload.Streams))
+	}
+	info := payload.Streams[0]
+	if info.Stream["level"] != "info" || info.Stream["app"] != "api" || len(info.Values) != 2 {
+		t.Errorf("unexpected info stream %+v", info)
+	}
+	if warn := payload.Streams[1]; warn.Stream["level"] != "warn" || len(warn.Values) != 1 {
+		t.Errorf("unexpected warn stream %+v", warn)
+	}
+}
+
+func TestHTTPSinkRetriesServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:       "test-http-retry",
+		Type:       "http",
+		URL:        server.URL,
+		MaxRetries: 2,
+	})
+	log.Info("retried")
+	if err := core.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+	if stats := sinkStats()["test-http-retry"]; stats.Sent != 1 || stats.Failed != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPSinkCountsFailedBatches(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:       "test-http-failed",
+		Type:       "http",
+		URL:        server.URL,
+		MaxRetries: -1,
+	})
+	log.Info("one")
+	log.Info("two")
+	if err := core.Sync(); err == nil {
+		t.Fatal("expected sync to report the export failure")
+	}
+
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("expected a single attempt with retries disabled, got %d", got)
+	}
+	if stats := sinkStats()["test-http-failed"]; stats.Failed != 2 || stats.Sent != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPSinkDropsWhenBufferFull(t *testing.T) {
+	exporting := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case exporting <- struct{}{}:
+		default:
+		}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	log, _ := newTestSink(t, SinkConfig{
+		Name:       "test-http-dropped",
+		Type:       "http",
+		URL:        server.URL,
+		BufferSize: 1,
+		BatchSize:  1,
+	})
+
+	// The worker blocks exporting the first entry, the second fills the
+	// buffer and the third has nowhere to go
+	log.Info("exporting")
+	select {
+	case <-exporting:
+	case <-time.After(5 * time.Second):
+		t.Fatal("first entry was not exported")
+	}
+	log.Info("buffered")
+	log.Info("dropped")
+
+	if stats := sinkStats()["test-http-dropped"]; stats.Dropped != 1 {
+		t.Errorf("expected 1 dropped entry, got %+v", stats)
+	}
+}
+
+func TestOTLPSinkPayload(t *testing.T) {
+	var request struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []otlpKeyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []otlpLogRecord `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/logs" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	log, core := newTestSink(t, SinkConfig{
+		Name:   "test-otlp",
+		Type:   "otlp",
+		URL:    server.URL + "/v1/logs",
+		Labels: map[string]string{"deployment.environment": "test"},
+	})
+	log.With(zap.String("trace_id", "0af7651916cd43dd8448eb211c80319c")).
+		Error("payment failed", zap.Int("attempt", 3))
+	if err := core.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if len(request.ResourceLogs) != 1 || len(request.ResourceLogs[0].ScopeLogs) != 1 {
+		t.Fatalf("unexpected request shape %+v", request)
+	}
+	resource := request.ResourceLogs[0].Resource.Attributes
+	if len(resource) != 2 || resource[0].Key != "deployment.environment" || resource[1].Key != "service.name" {
+		t.Errorf("unexpected resource attributes %+v", resource)
+	}
+
+	records := request.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("expected 1 log record, got %d", len(records))
+	}
+	record := records[0]
+	if record.SeverityNumber != 17 || record.SeverityText != "ERROR" {
+		t.Errorf("unexpected severity %d %s", record.SeverityNumber, record.SeverityText)
+	}
+	if record.Body["stringValue"] != "payment failed" {
+		t.Errorf("unexpected body %v", record.Body)
+	}
+	if record.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("trace_id was not mapped onto the record: %q", record.TraceID)
+	}
+	if len(record.Attributes) != 1 || record.Attributes[0].Key != "attempt" || record.Attributes[0].Value["intValue"] != "3" {
+		t.Errorf("unexpected attributes %+v", record.Attributes)
+	}
+	if stats := sinkStats()["test-otlp"]; stats.Sent != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,150 @@
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+)
+
+// profileTimeFormat sorts lexically, so pruning can rely on file names
+const profileTimeFormat = "20060102T150405Z"
+
+// StartContinuous writes a CPU and a heap profile to cfg.Directory every
+// interval and keeps the newest cfg.Retention of each kind. The returned
+// function stops the profiler and waits for a running capture to finish.
+func StartContinuous(cfg config.ContinuousProfilingConfig, log logger.Logger) (func(), error) {
+	if !cfg.Enabled {
+		return func() {}, nil
+	}
+
+	interval := time.Duration(cfg.Interval) * time.Second
+	cpuDuration := time.Duration(cfg.CPUDuration) * time.Second
+	if interval <= 0 || cpuDuration < 0 || cpuDuration >= interval {
+		return nil, fmt.Errorf("continuous profiling needs 0 <= cpu_duration < interval, got %s and %s", cpuDuration, interval)
+	}
+	if cfg.Retention <= 0 {
+		return nil, fmt.Errorf("continuous profiling retention must be positive, got %d", cfg.Retention)
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				capture(ctx, cfg, cpuDuration, log)
+			}
+		}
+	}()
+
+	log.Info("Continuous profiling started",
+		logger.String("directory", cfg.Directory),
+		logger.Duration("interval", interval),
+		logger.Duration("cpu_duration", cpuDuration),
+	)
+	return func() {
+		cancel()
+		wg.Wait()
+	}, nil
+}
+
+func capture(ctx context.Context, cfg config.ContinuousProfilingConfig, cpuDuration time.Duration, log logger.Logger) {
+	stamp := time.Now().UTC().Format(profileTimeFormat)
+
+	if cpuDuration > 0 {
+		// Fails while a CPU profile is taken through the admin API
+		err := writeProfile(cfg.Directory, "cpu-"+stamp+".pprof", func(f *os.File) error {
+			if err := pprof.StartCPUProfile(f); err != nil {
+				return err
+			}
+			timer := time.NewTimer(cpuDuration)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+			case <-timer.C:
+			}
+			pprof.StopCPUProfile()
+			return nil
+		})
+		if err != nil {
+			log.Warn("Skipped CPU profile", logger.Error(err))
+		}
+	}
+
+	err := writeProfile(cfg.Directory, "heap-"+stamp+".pprof", func(f *os.File) error {
+		return pprof.Lookup("heap").WriteTo(f, 0)
+	})
+	if err != nil {
+		log.Warn("Skipped heap profile", logger.Error(err))
+	}
+
+	for _, kind := range []string{"cpu-", "heap-"} {
+		if err := prune(cfg.Directory, kind, cfg.Retention); err != nil {
+			log.Warn("Failed to prune profiles", logger.String("kind", strings.TrimSuffix(kind, "-")), logger.Error(err))
+		}
+	}
+}
+
+// writeProfile writes to a temporary file first so readers never see a
+// partial profile
+func writeProfile(dir, name string, write func(*os.File) error) error {
+	f, err := os.CreateTemp(dir, ".profile-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, name))
+}
+
+// prune removes all but the newest keep profiles starting with prefix
+func prune(dir, prefix string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ".pprof") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= keep {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package profiling
+
+import (
+	"bytes"
+	"regexp"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[([^\]]+)\]:$`)
+	waitMinutes     = regexp.MustCompile(`^(\d+) minutes?$`)
+	frameOffset     = regexp.MustCompile(` \+0x[0-9a-f]+$`)
+	createdIn       = regexp.MustCompile(` in goroutine \d+$`)
+)
+
+// GoroutineGroup is a set of goroutines sharing the same stack
+type GoroutineGroup struct {
+	Count int `json:"count"`
+	// States counts the goroutines by wait reason, e.g. "chan receive"
+	States         map[string]int `json:"states"`
+	MaxWaitMinutes int            `json:"max_wait_minutes,omitempty"`
+	Stack          []string       `json:"stack"`
+}
+
+// GoroutineDump groups every goroutine by stack, largest groups first
+type GoroutineDump struct {
+	Total  int              `json:"total"`
+	Groups []GoroutineGroup `json:"groups"`
+}
+
+// DumpGoroutines captures all goroutine stacks. Argument values and PC
+// offsets are dropped so goroutines parked in the same place group together.
+func DumpGoroutines() (*GoroutineDump, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		return nil, err
+	}
+	return parseGoroutines(buf.String()), nil
+}
+
+func parseGoroutines(text string) *GoroutineDump {
+	groups := make(map[string]*GoroutineGroup)
+	dump := &GoroutineDump{}
+
+	for _, block := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		lines := strings.Split(block, "\n")
+		header := goroutineHeader.FindStringSubmatch(lines[0])
+		if header == nil {
+			continue
+		}
+		dump.Total++
+
+		state, wait := parseGoroutineState(header[1])
+		stack := parseStack(lines[1:])
+		key := strings.Join(stack, "\n")
+
+		group, ok := groups[key]
+		if !ok {
+			group = &GoroutineGroup{States: make(map[string]int), Stack: stack}
+			groups[key] = group
+		}
+		group.Count++
+		group.States[state]++
+		group.MaxWaitMinutes = max(group.MaxWaitMinutes, wait)
+	}
+
+	dump.Groups = make([]GoroutineGroup, 0, len(groups))
+	for _, group := range groups {
+		dump.Groups = append(dump.Groups, *group)
+	}
+	sort.Slice(dump.Groups, func(i, j int) bool {
+		a, b := dump.Groups[i], dump.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return strings.Join(a.Stack, "\n") < strings.Join(b.Stack, "\n")
+	})
+	return dump
+}
+
+// parseGoroutineState splits "select, 5 minutes, locked to thread" into the
+// wait reason and the minutes waited
+func parseGoroutineState(header string) (string, int) {
+	parts := strings.Split(header, ", ")
+	wait := 0
+	for _, part := range parts[1:] {
+		if m := waitMinutes.FindStringSubmatch(part); m != nil {
+			wait, _ = strconv.Atoi(m[1])
+		}
+	}
+	return parts[0], wait
+}
+
+// parseStack joins each function line with the file line that follows it
+func parseStack(lines []string) []string {
+	var frames []string
+	for _, line := range lines {
+		if location, ok := strings.CutPrefix(line, "\t"); ok && len(frames) > 0 {
+			frames[len(frames)-1] += " " + frameOffset.ReplaceAllString(location, "")
+			continue
+		}
+		frames = append(frames, stripArguments(createdIn.ReplaceAllString(line, "")))
+	}
+	return frames
+}
+
+// stripArguments drops the trailing argument list of a function line, keeping
+// receivers like (*Server) intact
+func stripArguments(line string) string {
+	if !strings.HasSuffix(line, ")") {
+		return line
+	}
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return line[:i] + "(...)"
+			}
+		}
+	}
+	return line
+}
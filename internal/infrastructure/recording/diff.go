@@ -0,0 +1,131 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Difference is one mismatch between a recorded and a replayed response
+type Difference struct {
+	Path     string `json:"path"` // status, header name or JSON path in the body
+	Recorded string `json:"recorded"`
+	Replayed string `json:"replayed"`
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: recorded %s, replayed %s", d.Path, d.Recorded, d.Replayed)
+}
+
+// DiffResponses compares status, the given headers and the bodies. JSON
+// bodies are compared structurally; ignore lists body paths such as
+// "data.updated_at" or "error.request_id" to leave out.
+func DiffResponses(recorded, replayed Response, headers, ignore []string) []Difference {
+	var diffs []Difference
+	if recorded.Status != replayed.Status {
+		diffs = append(diffs, Difference{Path: "status",
+			Recorded: fmt.Sprint(recorded.Status), Replayed: fmt.Sprint(replayed.Status)})
+	}
+	for _, name := range headers {
+		if a, b := recorded.Headers[name], replayed.Headers[name]; a != b {
+			diffs = append(diffs, Difference{Path: "header " + name, Recorded: quote(a), Replayed: quote(b)})
+		}
+	}
+
+	if recorded.BodyTruncated || recorded.BodyOmitted != "" {
+		// Only the recorded prefix can be compared
+		body := replayed.Body
+		if len(body) > len(recorded.Body) {
+			body = body[:len(recorded.Body)]
+		}
+		if body != recorded.Body {
+			diffs = append(diffs, Difference{Path: "body prefix", Recorded: quote(recorded.Body), Replayed: quote(body)})
+		}
+		return diffs
+	}
+
+	var a, b interface{}
+	if json.Unmarshal([]byte(recorded.Body), &a) == nil && json.Unmarshal([]byte(replayed.Body), &b) == nil {
+		ignored := make(map[string]bool, len(ignore))
+		for _, path := range ignore {
+			ignored[path] = true
+		}
+		return append(diffs, diffJSON("body", a, b, ignored)...)
+	}
+	if recorded.Body != replayed.Body {
+		diffs = append(diffs, Difference{Path: "body", Recorded: quote(recorded.Body), Replayed: quote(replayed.Body)})
+	}
+	return diffs
+}
+
+func diffJSON(path string, a, b interface{}, ignored map[string]bool) []Difference {
+	if ignored[strings.TrimPrefix(path, "body.")] {
+		return nil
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool, len(av)+len(bv))
+		for key := range av {
+			keys[key] = true
+		}
+		for key := range bv {
+			keys[key] = true
+		}
+		names := make([]string, 0, len(keys))
+		for key := range keys {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+
+		var diffs []Difference
+		for _, key := range names {
+			diffs = append(diffs, diffJSON(path+"."+key, av[key], bv[key], ignored)...)
+		}
+		return diffs
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		var diffs []Difference
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			var x, y interface{}
+			if i < len(av) {
+				x = av[i]
+			}
+			if i < len(bv) {
+				y = bv[i]
+			}
+			diffs = append(diffs, diffJSON(fmt.Sprintf("%s[%d]", path, i), x, y, ignored)...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []Difference{{Path: path, Recorded: jsonString(a), Replayed: jsonString(b)}}
+}
+
+func jsonString(value interface{}) string {
+	if value == nil {
+		return "(missing)"
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func quote(s string) string {
+	const limit = 200
+	if len(s) > limit {
+		s = s[:limit] + "..."
+	}
+	return fmt.Sprintf("%q", s)
+}
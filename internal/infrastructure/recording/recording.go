@@ -0,0 +1,274 @@
+// Package recording keeps sampled request/response pairs for debugging and
+// replaying. Exchanges are stored redacted and size-capped, either as JSON
+// files in a directory or in an in-memory ring buffer.
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+// ErrNotFound is returned by Get for unknown IDs
+var ErrNotFound = errors.New("recording not found")
+
+// Message is the recorded half of an exchange
+type Message struct {
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          string            `json:"body,omitempty"`
+	BodyBytes     int64             `json:"body_bytes"`               // Size before truncation
+	BodyTruncated bool              `json:"body_truncated,omitempty"` // Body holds only the first max_body_bytes
+	BodyOmitted   string            `json:"body_omitted,omitempty"`   // Why the body was not kept, e.g. binary
+}
+
+// Request is the recorded request
+type Request struct {
+	Method string `json:"method"`
+	URI    string `json:"uri"` // Path and redacted query
+	Host   string `json:"host,omitempty"`
+	Message
+}
+
+// Response is the recorded response
+type Response struct {
+	Status int `json:"status"`
+	Message
+}
+
+// Exchange is one recorded request/response pair
+type Exchange struct {
+	ID            string        `json:"id"`
+	Time          time.Time     `json:"time"`
+	Duration      time.Duration `json:"duration_ns"`
+	CorrelationID string        `json:"correlation_id,omitempty"`
+	Request       Request       `json:"request"`
+	Response      Response      `json:"response"`
+}
+
+// Summary lists an exchange without its bodies
+type Summary struct {
+	ID     string    `json:"id"`
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	URI    string    `json:"uri"`
+	Status int       `json:"status"`
+}
+
+func (e *Exchange) summary() Summary {
+	return Summary{ID: e.ID, Time: e.Time, Method: e.Request.Method, URI: e.Request.URI, Status: e.Response.Status}
+}
+
+// Store keeps recorded exchanges
+type Store interface {
+	Save(exchange *Exchange) error
+	// List returns the most recent exchanges first
+	List(limit int) ([]Summary, error)
+	Get(id string) (*Exchange, error)
+}
+
+const (
+	StoreDirectory = "directory"
+	StoreMemory    = "memory"
+
+	defaultMaxRecordings = 100
+)
+
+// New creates the store selected by cfg.Store
+func New(cfg config.RecorderConfig) (Store, error) {
+	maxRecordings := cfg.MaxRecordings
+	if maxRecordings <= 0 {
+		maxRecordings = defaultMaxRecordings
+	}
+
+	switch strings.ToLower(cfg.Store) {
+	case "", StoreMemory:
+		return NewMemoryStore(maxRecordings), nil
+	case StoreDirectory:
+		return NewDirectoryStore(cfg.Directory, maxRecordings)
+	default:
+		return nil, fmt.Errorf("unknown recorder store %q", cfg.Store)
+	}
+}
+
+// MemoryStore keeps the last exchanges in a ring buffer
+type MemoryStore struct {
+	mu    sync.RWMutex
+	ring  []*Exchange
+	next  int
+	count int
+}
+
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{ring: make([]*Exchange, size)}
+}
+
+func (m *MemoryStore) Save(exchange *Exchange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ring[m.next] = exchange
+	m.next = (m.next + 1) % len(m.ring)
+	if m.count < len(m.ring) {
+		m.count++
+	}
+	return nil
+}
+
+func (m *MemoryStore) List(limit int) ([]Summary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if limit <= 0 || limit > m.count {
+		limit = m.count
+	}
+	summaries := make([]Summary, 0, limit)
+	for i := 1; i <= limit; i++ {
+		exchange := m.ring[(m.next-i+len(m.ring))%len(m.ring)]
+		summaries = append(summaries, exchange.summary())
+	}
+	return summaries, nil
+}
+
+func (m *MemoryStore) Get(id string) (*Exchange, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := 0; i < m.count; i++ {
+		if exchange := m.ring[i]; exchange.ID == id {
+			return exchange, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// DirectoryStore writes one JSON file per exchange and deletes the oldest
+// files beyond its limit
+type DirectoryStore struct {
+	dir           string
+	maxRecordings int
+	mu            sync.Mutex
+}
+
+func NewDirectoryStore(dir string, maxRecordings int) (*DirectoryStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("recorder directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create recorder directory: %w", err)
+	}
+	return &DirectoryStore{dir: dir, maxRecordings: maxRecordings}, nil
+}
+
+// fileName sorts by time; the ID keeps names unique
+func fileName(exchange *Exchange) string {
+	return exchange.Time.UTC().Format("20060102T150405.000000000Z") + "_" + safeID(exchange.ID) + ".json"
+}
+
+func safeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+func (d *DirectoryStore) Save(exchange *Exchange) error {
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(d.dir, fileName(exchange)), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return d.prune()
+}
+
+// files returns recording file names, newest first
+func (d *DirectoryStore) files() ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+func (d *DirectoryStore) prune() error {
+	names, err := d.files()
+	if err != nil {
+		return err
+	}
+	for _, name := range names[min(len(names), d.maxRecordings):] {
+		if err := os.Remove(filepath.Join(d.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DirectoryStore) List(limit int) ([]Summary, error) {
+	names, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && limit < len(names) {
+		names = names[:limit]
+	}
+
+	summaries := make([]Summary, 0, len(names))
+	for _, name := range names {
+		exchange, err := ReadFile(filepath.Join(d.dir, name))
+		if err != nil {
+			continue // Pruned since it was listed
+		}
+		summaries = append(summaries, exchange.summary())
+	}
+	return summaries, nil
+}
+
+func (d *DirectoryStore) Get(id string) (*Exchange, error) {
+	names, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	suffix := "_" + safeID(id) + ".json"
+	for _, name := range names {
+		if strings.HasSuffix(name, suffix) {
+			return ReadFile(filepath.Join(d.dir, name))
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ReadFile loads an exchange written by DirectoryStore
+func ReadFile(path string) (*Exchange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var exchange Exchange
+	if err := json.Unmarshal(data, &exchange); err != nil {
+		return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+	}
+	return &exchange, nil
+}
@@ -0,0 +1,180 @@
+package sysinfo
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupV1Unlimited is the smallest memory.limit_in_bytes treated as no
+// limit; the kernel reports a page-aligned MaxInt64
+const cgroupV1Unlimited = 1 << 62
+
+// ErrNoCgroup is returned when the process does not run under a readable cgroup
+var ErrNoCgroup = errors.New("no cgroup found")
+
+// Cgroup holds the limits of the cgroup the process runs in. Zero limits
+// mean unlimited.
+type Cgroup struct {
+	Version     int     `json:"version"`
+	CPULimit    float64 `json:"cpu_limit,omitempty"`    // Cores, from the CFS quota
+	MemoryLimit uint64  `json:"memory_limit,omitempty"` // Bytes
+	MemoryUsage uint64  `json:"memory_usage,omitempty"` // Bytes, including page cache
+}
+
+// ReadCgroup reads the CPU and memory limits of the current process from
+// /sys/fs/cgroup, supporting both cgroup v1 and v2. With nested cgroups the
+// tightest limit on the path to the root applies.
+func ReadCgroup() (*Cgroup, error) {
+	paths, err := readProcCgroup()
+	if err != nil {
+		return nil, ErrNoCgroup
+	}
+
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		return readCgroupV2(paths[""]), nil
+	}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "memory")); err == nil {
+		return readCgroupV1(paths), nil
+	}
+	return nil, ErrNoCgroup
+}
+
+// readProcCgroup maps each controller in /proc/self/cgroup to its path; the
+// v2 hierarchy is keyed by ""
+func readProcCgroup() (map[string]string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths, scanner.Err()
+}
+
+func readCgroupV2(path string) *Cgroup {
+	cgroup := &Cgroup{Version: 2}
+
+	for _, dir := range cgroupDirs(cgroupRoot, path) {
+		if fields := readFields(filepath.Join(dir, "cpu.max")); len(fields) == 2 && fields[0] != "max" {
+			quota, err1 := strconv.ParseFloat(fields[0], 64)
+			period, err2 := strconv.ParseFloat(fields[1], 64)
+			if err1 == nil && err2 == nil && period > 0 {
+				cgroup.CPULimit = tighter(cgroup.CPULimit, quota/period)
+			}
+		}
+		if limit, ok := readUint(filepath.Join(dir, "memory.max")); ok {
+			cgroup.MemoryLimit = tighterUint(cgroup.MemoryLimit, limit)
+		}
+		if cgroup.MemoryUsage == 0 {
+			cgroup.MemoryUsage, _ = readUint(filepath.Join(dir, "memory.current"))
+		}
+	}
+	return cgroup
+}
+
+func readCgroupV1(paths map[string]string) *Cgroup {
+	cgroup := &Cgroup{Version: 1}
+
+	cpuPath, ok := paths["cpu"]
+	if !ok {
+		cpuPath = paths["cpu,cpuacct"]
+	}
+	for _, dir := range cgroupDirs(v1ControllerRoot("cpu", "cpu,cpuacct"), cpuPath) {
+		quota, err1 := strconv.ParseFloat(readString(filepath.Join(dir, "cpu.cfs_quota_us")), 64)
+		period, err2 := strconv.ParseFloat(readString(filepath.Join(dir, "cpu.cfs_period_us")), 64)
+		if err1 == nil && err2 == nil && quota > 0 && period > 0 {
+			cgroup.CPULimit = tighter(cgroup.CPULimit, quota/period)
+		}
+	}
+
+	for _, dir := range cgroupDirs(v1ControllerRoot("memory"), paths["memory"]) {
+		if limit, ok := readUint(filepath.Join(dir, "memory.limit_in_bytes")); ok && limit < cgroupV1Unlimited {
+			cgroup.MemoryLimit = tighterUint(cgroup.MemoryLimit, limit)
+		}
+		if cgroup.MemoryUsage == 0 {
+			cgroup.MemoryUsage, _ = readUint(filepath.Join(dir, "memory.usage_in_bytes"))
+		}
+	}
+	return cgroup
+}
+
+// v1ControllerRoot returns the mount point of a v1 controller, which may be
+// shared with others such as cpu,cpuacct
+func v1ControllerRoot(names ...string) string {
+	for _, name := range names {
+		dir := filepath.Join(cgroupRoot, name)
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+	}
+	return filepath.Join(cgroupRoot, names[0])
+}
+
+// cgroupDirs lists the directories from the process cgroup up to the mount
+// point. Inside a container the host path is usually not mounted, so only
+// existing directories are returned and the mount point is always included.
+func cgroupDirs(root, path string) []string {
+	var dirs []string
+	for path = filepath.Clean("/" + path); path != "/"; path = filepath.Dir(path) {
+		dir := filepath.Join(root, path)
+		if _, err := os.Stat(dir); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, root)
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readFields(path string) []string {
+	return strings.Fields(readString(path))
+}
+
+// readUint parses a single number; "max" and missing files report false
+func readUint(path string) (uint64, bool) {
+	value, err := strconv.ParseUint(readString(path), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// tighter returns the smaller limit, where 0 means none
+func tighter(current, limit float64) float64 {
+	if current == 0 {
+		return limit
+	}
+	return math.Min(current, limit)
+}
+
+func tighterUint(current, limit uint64) uint64 {
+	if current == 0 {
+		return limit
+	}
+	return min(current, limit)
+}
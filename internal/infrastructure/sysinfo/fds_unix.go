@@ -0,0 +1,14 @@
+//go:build !windows
+
+package sysinfo
+
+import "syscall"
+
+// maxFDs returns the soft limit on open file descriptors
+func maxFDs() uint64 {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0
+	}
+	return uint64(limit.Cur)
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package sysinfo
+
+// maxFDs is unknown on Windows, which limits handles differently
+func maxFDs() uint64 {
+	return 0
+}
@@ -0,0 +1,35 @@
+package sysinfo
+
+import "sync/atomic"
+
+// Panics counts panics recovered from request handlers by kind since startup
+type Panics struct {
+	Error uint64 `json:"error"`
+	Value uint64 `json:"value"`
+	Abort uint64 `json:"abort"`
+}
+
+var panicCounters struct {
+	error, value, abort atomic.Uint64
+}
+
+// CountPanic records a recovered panic; kind is "error", "value" or "abort"
+func CountPanic(kind string) {
+	switch kind {
+	case "error":
+		panicCounters.error.Add(1)
+	case "abort":
+		panicCounters.abort.Add(1)
+	default:
+		panicCounters.value.Add(1)
+	}
+}
+
+// RecoveredPanics reports the panics counted so far
+func RecoveredPanics() Panics {
+	return Panics{
+		Error: panicCounters.error.Load(),
+		Value: panicCounters.value.Load(),
+		Abort: panicCounters.abort.Load(),
+	}
+}
@@ -0,0 +1,68 @@
+package sysinfo
+
+import (
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Process describes the OS process. Values that cannot be read on this
+// platform are left out.
+type Process struct {
+	PID     int    `json:"pid"`
+	RSS     uint64 `json:"rss,omitempty"` // Bytes
+	OpenFDs int    `json:"open_fds,omitempty"`
+	MaxFDs  uint64 `json:"max_fds,omitempty"`
+}
+
+// Build identifies the running binary
+type Build struct {
+	GoVersion string `json:"go_version"`
+	Path      string `json:"path,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+func readProcess() Process {
+	process := Process{PID: os.Getpid()}
+
+	// statm reports sizes in pages: total, resident, ...
+	if fields := readFields("/proc/self/statm"); len(fields) > 1 {
+		if pages, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			process.RSS = pages * uint64(os.Getpagesize())
+		}
+	}
+	if entries, err := os.ReadDir("/proc/self/fd"); err == nil {
+		// One entry is the descriptor ReadDir itself opened
+		process.OpenFDs = len(entries) - 1
+	}
+	process.MaxFDs = maxFDs()
+	return process
+}
+
+func readBuild() *Build {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	build := &Build{
+		GoVersion: info.GoVersion,
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.Time = setting.Value
+		case "vcs.modified":
+			build.Modified = strings.EqualFold(setting.Value, "true")
+		}
+	}
+	return build
+}
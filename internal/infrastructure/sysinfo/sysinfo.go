@@ -0,0 +1,222 @@
+// Package sysinfo collects runtime, process and container diagnostics
+// without stopping the world: runtime numbers come from runtime/metrics
+// instead of runtime.ReadMemStats.
+package sysinfo
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metricHeapAlloc   = "/memory/classes/heap/objects:bytes"
+	metricTotalAlloc  = "/gc/heap/allocs:bytes"
+	metricSys         = "/memory/classes/total:bytes"
+	metricHeapObjects = "/gc/heap/objects:objects"
+	metricHeapGoal    = "/gc/heap/goal:bytes"
+	metricStacks      = "/memory/classes/heap/stacks:bytes"
+	metricMemoryLimit = "/gc/gomemlimit:bytes"
+	metricGCCycles    = "/gc/cycles/total:gc-cycles"
+	metricGCForced    = "/gc/cycles/forced:gc-cycles"
+	metricGOGC        = "/gc/gogc:percent"
+	metricGCPauses    = "/sched/pauses/total/gc:seconds"
+	metricGoroutines  = "/sched/goroutines:goroutines"
+	metricGOMAXPROCS  = "/sched/gomaxprocs:threads"
+	metricThreads     = "/sched/threads/total:threads"
+
+	// Goroutine state metrics only exist in newer runtimes
+	goroutineStatePrefix = "/sched/goroutines/"
+)
+
+// Snapshot is one collection of diagnostics
+type Snapshot struct {
+	CollectedAt time.Time  `json:"collected_at"`
+	GOMAXPROCS  int        `json:"gomaxprocs"`
+	Threads     uint64     `json:"threads,omitempty"`
+	Memory      Memory     `json:"memory"`
+	GC          GC         `json:"gc"`
+	Goroutines  Goroutines `json:"goroutines"`
+	// Cgroup is nil when the process does not run under a readable cgroup
+	Cgroup *Cgroup `json:"cgroup,omitempty"`
+	// Tuning is nil unless ApplyRuntimeLimits ran at startup
+	Tuning  *Tuning `json:"tuning,omitempty"`
+	Process Process `json:"process"`
+	Build   *Build  `json:"build,omitempty"`
+}
+
+// Memory figures are in bytes. The first three keep the meaning of the
+// runtime.MemStats fields they replace.
+type Memory struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+	HeapObjects uint64 `json:"heap_objects"`
+	HeapGoal    uint64 `json:"heap_goal"`
+	Stacks      uint64 `json:"stacks"`
+	// Limit is GOMEMLIMIT; it is left out when unset
+	Limit uint64 `json:"limit,omitempty"`
+}
+
+// GC pause percentiles are upper bounds of runtime histogram buckets
+type GC struct {
+	Cycles       uint64  `json:"cycles"`
+	Forced       uint64  `json:"forced"`
+	GOGC         uint64  `json:"gogc"` // 0 when GOGC=off
+	PauseP50Ms   float64 `json:"pause_p50_ms"`
+	PauseP90Ms   float64 `json:"pause_p90_ms"`
+	PauseP99Ms   float64 `json:"pause_p99_ms"`
+	PauseMaxMs   float64 `json:"pause_max_ms"`
+	PauseSamples uint64  `json:"pause_samples"`
+}
+
+// Goroutines are counted by scheduler state when the runtime reports it.
+// Older runtimes only report the total; a per wait reason breakdown needs a
+// stop-the-world stack dump, which the admin goroutines endpoint provides.
+type Goroutines struct {
+	Total  uint64            `json:"total"`
+	States map[string]uint64 `json:"states,omitempty"`
+}
+
+// Collector caches snapshots for ttl, so polling dashboards do not add load
+type Collector struct {
+	ttl     time.Duration
+	samples []metrics.Sample
+	states  []string
+
+	mu   sync.Mutex
+	last *Snapshot
+}
+
+func NewCollector(ttl time.Duration) *Collector {
+	names := []string{
+		metricHeapAlloc, metricTotalAlloc, metricSys, metricHeapObjects, metricHeapGoal,
+		metricStacks, metricMemoryLimit, metricGCCycles, metricGCForced, metricGOGC,
+		metricGCPauses, metricGoroutines, metricGOMAXPROCS, metricThreads,
+	}
+
+	var states []string
+	for _, description := range metrics.All() {
+		if state, ok := strings.CutPrefix(description.Name, goroutineStatePrefix); ok {
+			names = append(names, description.Name)
+			states = append(states, strings.TrimSuffix(state, ":goroutines"))
+		}
+	}
+
+	samples := make([]metrics.Sample, len(names))
+	for i, name := range names {
+		samples[i].Name = name
+	}
+	return &Collector{ttl: ttl, samples: samples, states: states}
+}
+
+// Snapshot returns the cached snapshot, collecting a new one once it is
+// older than the ttl. Callers must not modify it.
+func (c *Collector) Snapshot() *Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CollectedAt) < c.ttl {
+		return c.last
+	}
+	c.last = c.collect()
+	return c.last
+}
+
+func (c *Collector) collect() *Snapshot {
+	metrics.Read(c.samples)
+	values := make(map[string]metrics.Value, len(c.samples))
+	for _, sample := range c.samples {
+		values[sample.Name] = sample.Value
+	}
+	number := func(name string) uint64 {
+		if value := values[name]; value.Kind() == metrics.KindUint64 {
+			return value.Uint64()
+		}
+		return 0
+	}
+
+	snapshot := &Snapshot{
+		CollectedAt: time.Now(),
+		GOMAXPROCS:  int(number(metricGOMAXPROCS)),
+		Threads:     number(metricThreads),
+		Memory: Memory{
+			Alloc:       number(metricHeapAlloc),
+			TotalAlloc:  number(metricTotalAlloc),
+			Sys:         number(metricSys),
+			HeapObjects: number(metricHeapObjects),
+			HeapGoal:    number(metricHeapGoal),
+			Stacks:      number(metricStacks),
+		},
+		GC: GC{
+			Cycles: number(metricGCCycles),
+			Forced: number(metricGCForced),
+			GOGC:   number(metricGOGC),
+		},
+		Goroutines: Goroutines{Total: number(metricGoroutines)},
+		Tuning:     AppliedTuning(),
+		Process:    readProcess(),
+		Build:      readBuild(),
+	}
+	if snapshot.GOMAXPROCS == 0 {
+		snapshot.GOMAXPROCS = runtime.GOMAXPROCS(0)
+	}
+	if limit := number(metricMemoryLimit); limit < math.MaxInt64 {
+		snapshot.Memory.Limit = limit
+	}
+
+	if value := values[metricGCPauses]; value.Kind() == metrics.KindFloat64Histogram {
+		pauses := value.Float64Histogram()
+		snapshot.GC.PauseSamples = histogramCount(pauses)
+		snapshot.GC.PauseP50Ms = histogramQuantile(pauses, 0.5) * 1000
+		snapshot.GC.PauseP90Ms = histogramQuantile(pauses, 0.9) * 1000
+		snapshot.GC.PauseP99Ms = histogramQuantile(pauses, 0.99) * 1000
+		snapshot.GC.PauseMaxMs = histogramQuantile(pauses, 1) * 1000
+	}
+
+	if len(c.states) > 0 {
+		snapshot.Goroutines.States = make(map[string]uint64, len(c.states))
+		for _, state := range c.states {
+			snapshot.Goroutines.States[state] = number(goroutineStatePrefix + state + ":goroutines")
+		}
+	}
+
+	if cgroup, err := ReadCgroup(); err == nil {
+		snapshot.Cgroup = cgroup
+	}
+	return snapshot
+}
+
+func histogramCount(h *metrics.Float64Histogram) uint64 {
+	var total uint64
+	for _, count := range h.Counts {
+		total += count
+	}
+	return total
+}
+
+// histogramQuantile returns the upper bound of the bucket holding quantile
+// q, falling back to the lower bound for the open-ended last bucket
+func histogramQuantile(h *metrics.Float64Histogram, q float64) float64 {
+	total := histogramCount(h)
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(total)))
+	var seen uint64
+	for i, count := range h.Counts {
+		seen += count
+		if count == 0 || seen < rank {
+			continue
+		}
+		if upper := h.Buckets[i+1]; !math.IsInf(upper, 1) {
+			return upper
+		}
+		return h.Buckets[i]
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package sysinfo
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+// Sources of a tuned runtime setting
+const (
+	SourceDefault = "default" // Go's own choice
+	SourceEnv     = "env"     // GOMAXPROCS or GOMEMLIMIT was set
+	SourceCgroup  = "cgroup"  // Derived from the container limits
+)
+
+// Tuning records the GOMAXPROCS and GOMEMLIMIT chosen at startup
+type Tuning struct {
+	GOMAXPROCS        int    `json:"gomaxprocs"`
+	GOMAXPROCSSource  string `json:"gomaxprocs_source"`
+	MemoryLimit       int64  `json:"memory_limit,omitempty"` // Bytes; left out when unlimited
+	MemoryLimitSource string `json:"memory_limit_source"`
+}
+
+var appliedTuning atomic.Pointer[Tuning]
+
+// AppliedTuning returns what ApplyRuntimeLimits chose, or nil before it ran
+func AppliedTuning() *Tuning {
+	return appliedTuning.Load()
+}
+
+// ApplyRuntimeLimits sets GOMAXPROCS to the cgroup CPU quota, rounded down
+// and at least cfg.MinMaxProcs, and GOMEMLIMIT to cfg.MemLimitRatio of the
+// cgroup memory limit. Settings given through the environment are kept.
+func ApplyRuntimeLimits(cfg config.RuntimeConfig) (*Tuning, error) {
+	if cfg.AutoMemLimit && (cfg.MemLimitRatio <= 0 || cfg.MemLimitRatio > 1) {
+		return nil, fmt.Errorf("server.runtime.memlimit_ratio must be in (0, 1], got %g", cfg.MemLimitRatio)
+	}
+
+	var cgroup *Cgroup
+	if cfg.AutoMaxProcs || cfg.AutoMemLimit {
+		// Outside a cgroup the Go defaults stay in place
+		cgroup, _ = ReadCgroup()
+	}
+
+	tuning := &Tuning{
+		GOMAXPROCSSource:  SourceDefault,
+		MemoryLimitSource: SourceDefault,
+	}
+
+	switch {
+	case os.Getenv("GOMAXPROCS") != "":
+		tuning.GOMAXPROCSSource = SourceEnv
+	case cfg.AutoMaxProcs && cgroup != nil && cgroup.CPULimit > 0:
+		procs := max(int(math.Floor(cgroup.CPULimit)), cfg.MinMaxProcs, 1)
+		runtime.GOMAXPROCS(min(procs, runtime.NumCPU()))
+		tuning.GOMAXPROCSSource = SourceCgroup
+	}
+	tuning.GOMAXPROCS = runtime.GOMAXPROCS(0)
+
+	switch {
+	case os.Getenv("GOMEMLIMIT") != "":
+		tuning.MemoryLimitSource = SourceEnv
+	case cfg.AutoMemLimit && cgroup != nil && cgroup.MemoryLimit > 0:
+		debug.SetMemoryLimit(int64(float64(cgroup.MemoryLimit) * cfg.MemLimitRatio))
+		tuning.MemoryLimitSource = SourceCgroup
+	}
+	// A negative input only reads the current limit
+	if limit := debug.SetMemoryLimit(-1); limit < math.MaxInt64 {
+		tuning.MemoryLimit = limit
+	}
+
+	appliedTuning.Store(tuning)
+	return tuning, nil
+}
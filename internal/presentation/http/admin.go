@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http/pprof"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/presentation/http/handlers"
+	"go-clean-template/internal/presentation/http/middlewares"
+)
+
+// SetupAdminRoutes builds the router served on the admin listener. Every
+// route requires the admin token or a verified client certificate and is
+// audited. public is the API router listed by /routes.
+func SetupAdminRoutes(cfg *config.Config, log logger.Logger, deps *RouteDeps, public chi.Routes) *chi.Mux {
+	r := chi.NewRouter()
+
+	adminLog := log.Named("admin")
+
+	r.Use(middlewares.RequestID())
+	r.Use(middlewares.CorrelationID())
+	r.Use(middlewares.ContextLogger(log))
+	r.Use(middlewares.Recoverer(adminLog))
+	r.Use(middlewares.RequestLogger(config.HTTPLoggingConfig{}, adminLog))
+	r.Use(middlewares.AdminAuth(cfg.Admin.Token, deps.Audit, adminLog))
+	r.Use(middlewares.AuditAdmin(deps.Audit, adminLog))
+	r.Use(middlewares.CacheControl(middlewares.NoStorePolicy))
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(requestTimeout))
+
+		healthHandler := handlers.NewHealthHandler(deps.Probes, adminLog)
+		r.Get("/system", healthHandler.SystemInfo)
+
+		adminHandler := handlers.NewAdminHandler(public, deps.RateLimits, adminLog)
+		r.Get("/config", adminHandler.Config)
+		r.Get("/routes", adminHandler.Routes)
+		r.Get("/rate-limits", adminHandler.RateLimits)
+		r.Delete("/rate-limits", adminHandler.ResetRateLimits)
+		r.Delete("/rate-limits/{client}", adminHandler.ResetRateLimit)
+
+		if levels := logger.Levels(log); levels != nil {
+			logLevelHandler := handlers.NewLogLevelHandler(levels,
+				time.Duration(cfg.Logging.LevelControl.MaxTTL)*time.Second, adminLog)
+			r.Get("/log-level", logLevelHandler.Get)
+			r.Put("/log-level", logLevelHandler.Set)
+			r.Delete("/log-level", logLevelHandler.Reset)
+		}
+
+		if deps.Recordings != nil {
+			recordingsHandler := handlers.NewRecordingsHandler(deps.Recordings, adminLog)
+			r.Get("/recordings", recordingsHandler.List)
+			r.Get("/recordings/*", recordingsHandler.Get)
+		}
+	})
+
+	// Profiles and traces run for as long as the caller asks, up to
+	// profiling.max_duration, so they are kept out of the request timeout
+	if cfg.Profiling.Endpoints {
+		profilingHandler := handlers.NewProfilingHandler(
+			time.Duration(cfg.Profiling.MaxDuration)*time.Second, adminLog)
+
+		r.Route("/debug", func(r chi.Router) {
+			r.Get("/goroutines", profilingHandler.Goroutines)
+			r.Get("/pprof/profile", profilingHandler.Profile)
+			r.Get("/pprof/trace", profilingHandler.Trace)
+			r.Get("/pprof/cmdline", pprof.Cmdline)
+			r.HandleFunc("/pprof/symbol", pprof.Symbol)
+			// Index also serves named profiles such as /pprof/heap
+			r.Get("/pprof/*", pprof.Index)
+		})
+	}
+
+	return r
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/presentation/http/middlewares"
+	"go-clean-template/internal/shared/errors"
+	"go-clean-template/internal/shared/response"
+)
+
+// AdminHandler serves the operational endpoints of the admin listener
+type AdminHandler struct {
+	routes     chi.Routes
+	rateLimits *middlewares.ClientLimiterStore
+	redactor   *logger.Redactor
+	logger     logger.Logger
+}
+
+// NewAdminHandler describes the public router routes and exposes its rate limiter store
+func NewAdminHandler(routes chi.Routes, rateLimits *middlewares.ClientLimiterStore, log logger.Logger) *AdminHandler {
+	// The config dump always uses the default rules, even when log
+	// redaction is turned off
+	redactor, _ := logger.NewRedactor(logger.RedactionConfig{})
+
+	return &AdminHandler{
+		routes:     routes,
+		rateLimits: rateLimits,
+		redactor:   redactor,
+		logger:     log,
+	}
+}
+
+// RouteInfo describes one route of the public router
+type RouteInfo struct {
+	Method      string `json:"method"`
+	Pattern     string `json:"pattern"`
+	Middlewares int    `json:"middlewares"`
+}
+
+// Config returns the effective configuration with secrets masked
+func (h *AdminHandler) Config(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, r, h.redactor.Value("", maskSettings("", config.Settings())))
+}
+
+// maskSettings hides what the key denylist cannot tell apart: every value of
+// a headers map, which usually carries API keys, and credentials in URLs
+func maskSettings(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return maskURLUserinfo(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for nestedKey, nested := range v {
+			if key == "headers" {
+				masked[nestedKey] = logger.RedactedValue
+				continue
+			}
+			masked[nestedKey] = maskSettings(nestedKey, nested)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, nested := range v {
+			masked[i] = maskSettings("", nested)
+		}
+		return masked
+	default:
+		return value
+	}
+}
+
+// maskURLUserinfo replaces the user and password of an absolute URL
+func maskURLUserinfo(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil || u.Host == "" {
+		return s
+	}
+	u.User = nil
+	return strings.Replace(u.String(), "://", "://"+logger.RedactedValue+"@", 1)
+}
+
+// Routes lists the routes of the public router
+func (h *AdminHandler) Routes(w http.ResponseWriter, r *http.Request) {
+	var routes []RouteInfo
+	err := chi.Walk(h.routes, func(method, route string, _ http.Handler, mws ...func(http.Handler) http.Handler) error {
+		routes = append(routes, RouteInfo{
+			Method:      method,
+			Pattern:     strings.ReplaceAll(route, "/*/", "/"),
+			Middlewares: len(mws),
+		})
+		return nil
+	})
+	if err != nil {
+		response.ErrorFromAppError(w, r, errors.InternalServerWithCause("ROUTES_UNAVAILABLE", "Routes could not be listed", err))
+		return
+	}
+	response.Success(w, r, routes)
+}
+
+// RateLimits lists clients with requests in the current rate limit window
+func (h *AdminHandler) RateLimits(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, r, h.rateLimits.Snapshot())
+}
+
+// ResetRateLimit clears one client's rate limit window
+func (h *AdminHandler) ResetRateLimit(w http.ResponseWriter, r *http.Request) {
+	client := chi.URLParam(r, "client")
+	if !h.rateLimits.Reset(client) {
+		response.ErrorFromAppError(w, r, errors.NotFound("CLIENT_NOT_FOUND", "No rate limit window for this client"))
+		return
+	}
+
+	h.logger.WithContext(r.Context()).Warn("Rate limit reset", logger.String("client", client))
+	response.Success(w, r, map[string]int{"reset": 1})
+}
+
+// ResetRateLimits clears every client's rate limit window
+func (h *AdminHandler) ResetRateLimits(w http.ResponseWriter, r *http.Request) {
+	count := h.rateLimits.ResetAll()
+
+	h.logger.WithContext(r.Context()).Warn("All rate limits reset", logger.Int("clients", count))
+	response.Success(w, r, map[string]int{"reset": count})
+}
@@ -3,52 +3,83 @@ package handlers
 import (
 	"net/http"
 	"runtime"
+	"strconv"
 	"time"
 
+	"go-clean-template/internal/infrastructure/health"
 	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/sysinfo"
 	"go-clean-template/internal/shared/response"
 )
 
+// probeFailureLogInterval limits warnings from failing probes, which are
+// polled every few seconds
+const probeFailureLogInterval = time.Minute
+
 type HealthHandler struct {
+	probes *health.Probes
 	logger logger.Logger
 }
 
-func NewHealthHandler(log logger.Logger) *HealthHandler {
+func NewHealthHandler(probes *health.Probes, log logger.Logger) *HealthHandler {
 	return &HealthHandler{
+		probes: probes,
 		logger: log,
 	}
 }
 
-// HealthResponse represents the health check response
+// HealthResponse represents the health check response. Checks maps each
+// check to its status; ?verbose adds the full results.
 type HealthResponse struct {
-	Status    string            `json:"status"`
-	Timestamp time.Time         `json:"timestamp"`
-	Service   string            `json:"service"`
-	Version   string            `json:"version"`
-	Uptime    string            `json:"uptime,omitempty"`
-	Checks    map[string]string `json:"checks,omitempty"`
+	Status    string                   `json:"status"`
+	Timestamp time.Time                `json:"timestamp"`
+	Service   string                   `json:"service"`
+	Version   string                   `json:"version"`
+	Uptime    string                   `json:"uptime,omitempty"`
+	Checks    map[string]string        `json:"checks,omitempty"`
+	Details   []health.Result          `json:"details,omitempty"`
+	Probes    map[string]health.Report `json:"probes,omitempty"`
 }
 
 // SystemInfoResponse represents system information
 type SystemInfoResponse struct {
-	Status       string            `json:"status"`
-	Timestamp    time.Time         `json:"timestamp"`
-	Service      string            `json:"service"`
-	Version      string            `json:"version"`
-	GoVersion    string            `json:"go_version"`
-	NumCPU       int               `json:"num_cpu"`
-	NumGoroutine int               `json:"num_goroutine"`
-	Memory       map[string]uint64 `json:"memory"`
-	Uptime       string            `json:"uptime"`
+	Status       string             `json:"status"`
+	Timestamp    time.Time          `json:"timestamp"`
+	Service      string             `json:"service"`
+	Version      string             `json:"version"`
+	GoVersion    string             `json:"go_version"`
+	NumCPU       int                `json:"num_cpu"`
+	NumGoroutine int                `json:"num_goroutine"`
+	GOMAXPROCS   int                `json:"gomaxprocs"`
+	Threads      uint64             `json:"threads,omitempty"`
+	Memory       sysinfo.Memory     `json:"memory"`
+	GC           sysinfo.GC         `json:"gc"`
+	Goroutines   sysinfo.Goroutines `json:"goroutines"`
+	Cgroup       *sysinfo.Cgroup    `json:"cgroup,omitempty"`
+	Tuning       *sysinfo.Tuning    `json:"tuning,omitempty"`
+	Process      sysinfo.Process    `json:"process"`
+	Build        *sysinfo.Build     `json:"build,omitempty"`
+	CollectedAt  time.Time          `json:"collected_at"`
+	Logging      logger.Stats       `json:"logging"`
+	Panics       sysinfo.Panics     `json:"panics"`
+	Uptime       string             `json:"uptime"`
 }
 
 var startTime = time.Now()
 
+// systemInfoTTL bounds how often runtime diagnostics are collected
+const systemInfoTTL = 2 * time.Second
+
+// systemInfo is shared by every handler so the cache spans listeners
+var systemInfo = sysinfo.NewCollector(systemInfoTTL)
+
 // @Summary Get health status
-// @Description Returns the basic health status of the service
+// @Description Aggregates the startup, liveness and readiness probes. Returns 503 when any of them fails; pass verbose for every check result.
 // @Tags Health
 // @Produce json
+// @Param verbose query bool false "Include the result of every check"
 // @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
 // @Router /health [get]
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Health check endpoint called",
@@ -56,15 +87,36 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		logger.String("path", r.URL.Path),
 	)
 
-	response.Success(w, HealthResponse{
+	probes := map[string]health.Report{
+		"startup":   h.probes.Startup(),
+		"liveness":  h.probes.Liveness(),
+		"readiness": h.probes.Readiness(r.Context()),
+	}
+
+	result := HealthResponse{
 		Status:    "healthy",
 		Timestamp: time.Now(),
 		Service:   "go-clean-template",
 		Version:   "1.0.0",
 		Uptime:    time.Since(startTime).String(),
-	})
+		Checks:    make(map[string]string, len(probes)),
+	}
+	statusCode := http.StatusOK
+	for name, report := range probes {
+		result.Checks[name] = report.Status
+		switch {
+		case !report.Passing():
+			result.Status = "unhealthy"
+			statusCode = http.StatusServiceUnavailable
+		case report.Status == health.StatusWarn && result.Status == "healthy":
+			result.Status = "degraded"
+		}
+	}
+	if isVerbose(r) {
+		result.Probes = probes
+	}
 
-	h.logger.Debug("Health check completed successfully")
+	response.WithStatus(w, r, statusCode, result)
 }
 
 // @Summary Get heartbeat
@@ -76,89 +128,119 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 func (h *HealthHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Heartbeat endpoint called")
 
-	response.Success(w, map[string]interface{}{
+	response.Success(w, r, map[string]interface{}{
 		"status":    "alive",
 		"timestamp": time.Now(),
 		"service":   "go-clean-template",
 	})
 }
 
-// @Summary Get system information
-// @Description Returns detailed system information including memory usage, CPU count, and runtime stats
-// @Tags Health
-// @Produce json
-// @Success 200 {object} SystemInfoResponse
-// @Router /system [get]
+// SystemInfo reports memory, GC, goroutine, cgroup and process statistics;
+// it is served on the admin listener. Runtime figures may be up to
+// systemInfoTTL old, see collected_at.
 func (h *HealthHandler) SystemInfo(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("System info endpoint called")
 
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
+	snapshot := systemInfo.Snapshot()
 
-	response.Success(w, SystemInfoResponse{
+	response.Success(w, r, SystemInfoResponse{
 		Status:       "healthy",
 		Timestamp:    time.Now(),
 		Service:      "go-clean-template",
 		Version:      "1.0.0",
 		GoVersion:    runtime.Version(),
 		NumCPU:       runtime.NumCPU(),
-		NumGoroutine: runtime.NumGoroutine(),
-		Memory: map[string]uint64{
-			"alloc":       m.Alloc,
-			"total_alloc": m.TotalAlloc,
-			"sys":         m.Sys,
-			"num_gc":      uint64(m.NumGC),
-		},
-		Uptime: time.Since(startTime).String(),
+		NumGoroutine: int(snapshot.Goroutines.Total),
+		GOMAXPROCS:   snapshot.GOMAXPROCS,
+		Threads:      snapshot.Threads,
+		Memory:       snapshot.Memory,
+		GC:           snapshot.GC,
+		Goroutines:   snapshot.Goroutines,
+		Cgroup:       snapshot.Cgroup,
+		Tuning:       snapshot.Tuning,
+		Process:      snapshot.Process,
+		Build:        snapshot.Build,
+		CollectedAt:  snapshot.CollectedAt,
+		Logging:      logger.Metrics(),
+		Panics:       sysinfo.RecoveredPanics(),
+		Uptime:       time.Since(startTime).String(),
 	})
 
 	h.logger.Debug("System info completed successfully")
 }
 
+// @Summary Get startup status
+// @Description Startup probe. Returns 503 until initialization, such as binding the listener, has completed.
+// @Tags Health
+// @Produce json
+// @Param verbose query bool false "Include the result of every check"
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /startup [get]
+func (h *HealthHandler) Startup(w http.ResponseWriter, r *http.Request) {
+	h.writeProbe(w, r, "startup", h.probes.Startup(), "started", "starting")
+}
+
 // @Summary Get readiness status
-// @Description Checks if the service is ready to serve requests by verifying dependencies
+// @Description Readiness probe. Returns 503 before startup completed, while draining for shutdown, or when a critical dependency check fails.
 // @Tags Health
 // @Produce json
+// @Param verbose query bool false "Include the result of every check"
 // @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
 // @Router /ready [get]
 func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
-	h.logger.Debug("Readiness check endpoint called")
-
-	// In a real application, you would check dependencies here
-	// e.g., database connectivity, external services, etc.
-	checks := map[string]string{
-		"database": "healthy", // This would be a real check
-		"redis":    "healthy", // This would be a real check
-		"storage":  "healthy", // This would be a real check
-	}
-
-	response.Success(w, HealthResponse{
-		Status:    "ready",
-		Timestamp: time.Now(),
-		Service:   "go-clean-template",
-		Version:   "1.0.0",
-		Checks:    checks,
-	})
-
-	h.logger.Debug("Readiness check completed successfully")
+	h.writeProbe(w, r, "readiness", h.probes.Readiness(r.Context()), "ready", "not_ready")
 }
 
 // @Summary Get liveness status
-// @Description Checks if the service is alive and responding
+// @Description Liveness probe. Returns 503 when the internal heartbeat watchdog detects a wedged process; dependencies are not checked.
 // @Tags Health
 // @Produce json
+// @Param verbose query bool false "Include the result of every check"
 // @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
 // @Router /live [get]
 func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
-	h.logger.Debug("Liveness check endpoint called")
+	h.writeProbe(w, r, "liveness", h.probes.Liveness(), "alive", "wedged")
+}
 
-	response.Success(w, HealthResponse{
-		Status:    "alive",
+// writeProbe answers a probe with 200 or, when it fails, 503
+func (h *HealthHandler) writeProbe(w http.ResponseWriter, r *http.Request, probe string, report health.Report, passing, failing string) {
+	result := HealthResponse{
+		Status:    passing,
 		Timestamp: time.Now(),
 		Service:   "go-clean-template",
 		Version:   "1.0.0",
 		Uptime:    time.Since(startTime).String(),
-	})
+		Checks:    make(map[string]string, len(report.Checks)),
+	}
+	for _, check := range report.Checks {
+		result.Checks[check.Name] = check.Status
+	}
+	if isVerbose(r) {
+		result.Details = report.Checks
+	}
+
+	statusCode := http.StatusOK
+	if !report.Passing() {
+		result.Status = failing
+		statusCode = http.StatusServiceUnavailable
+		h.logger.OncePer("probe_failed_"+probe, probeFailureLogInterval).Warn("Probe failing",
+			logger.String("probe", probe),
+			logger.Any("checks", result.Checks),
+		)
+	}
+
+	response.WithStatus(w, r, statusCode, result)
+}
 
-	h.logger.Debug("Liveness check completed successfully")
+// isVerbose reports whether ?verbose is set and not false
+func isVerbose(r *http.Request) bool {
+	values, ok := r.URL.Query()["verbose"]
+	if !ok {
+		return false
+	}
+	verbose, err := strconv.ParseBool(values[0])
+	return err != nil || verbose
 }
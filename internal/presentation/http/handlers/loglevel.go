@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/errors"
+	"go-clean-template/internal/shared/request"
+	"go-clean-template/internal/shared/response"
+)
+
+type LogLevelHandler struct {
+	levels *logger.LevelController
+	maxTTL time.Duration
+	logger logger.Logger
+}
+
+func NewLogLevelHandler(levels *logger.LevelController, maxTTL time.Duration, log logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{
+		levels: levels,
+		maxTTL: maxTTL,
+		logger: log,
+	}
+}
+
+// LogLevelRequest changes the global level, or a module's level when Module is set
+type LogLevelRequest struct {
+	Level  string `json:"level"`
+	Module string `json:"module,omitempty"`
+	TTL    int    `json:"ttl,omitempty"` // Seconds before the change reverts; 0 keeps it
+}
+
+// Get returns the active global and per-module log levels and pending reverts
+func (h *LogLevelHandler) Get(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, r, h.levels.Snapshot())
+}
+
+// Set changes the global or a module's log level, optionally reverting after a TTL
+func (h *LogLevelHandler) Set(w http.ResponseWriter, r *http.Request) {
+	req, appErr := request.Decode[LogLevelRequest](w, r)
+	if appErr != nil {
+		response.ErrorFromAppError(w, r, appErr)
+		return
+	}
+
+	ttl := time.Duration(req.TTL) * time.Second
+	if req.TTL < 0 || (h.maxTTL > 0 && ttl > h.maxTTL) {
+		response.ErrorFromAppError(w, r, errors.BadRequest("INVALID_TTL",
+			"ttl must be between 0 and "+h.maxTTL.String()))
+		return
+	}
+
+	var err error
+	if req.Module != "" {
+		err = h.levels.SetModuleLevel(req.Module, req.Level, ttl)
+	} else {
+		err = h.levels.SetLevel(req.Level, ttl)
+	}
+	if err != nil {
+		response.ErrorFromAppError(w, r, errors.BadRequestWithCause("INVALID_LOG_LEVEL", err.Error(), err))
+		return
+	}
+
+	h.logger.WithContext(r.Context()).Warn("Log level changed",
+		logger.String("level", req.Level),
+		logger.String("module", req.Module),
+		logger.Duration("ttl", ttl),
+		logger.String("remote_addr", r.RemoteAddr),
+	)
+	response.Success(w, r, h.levels.Snapshot())
+}
+
+// Reset restores the configured global and per-module log levels
+func (h *LogLevelHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	h.levels.Reset()
+
+	h.logger.WithContext(r.Context()).Warn("Log levels reset",
+		logger.String("level", h.levels.Level().String()),
+		logger.String("remote_addr", r.RemoteAddr),
+	)
+	response.Success(w, r, h.levels.Snapshot())
+}
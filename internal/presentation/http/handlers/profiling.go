@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"time"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/profiling"
+	"go-clean-template/internal/shared/errors"
+	"go-clean-template/internal/shared/response"
+)
+
+// ProfilingHandler serves runtime profiles on the admin listener. CPU
+// profiles and traces are capped at maxDuration.
+type ProfilingHandler struct {
+	maxDuration time.Duration
+	logger      logger.Logger
+}
+
+func NewProfilingHandler(maxDuration time.Duration, log logger.Logger) *ProfilingHandler {
+	return &ProfilingHandler{
+		maxDuration: maxDuration,
+		logger:      log,
+	}
+}
+
+// Profile captures a CPU profile for ?seconds (30 by default)
+func (h *ProfilingHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	h.limited(w, r, 30*time.Second, pprof.Profile)
+}
+
+// Trace captures an execution trace for ?seconds (1 by default)
+func (h *ProfilingHandler) Trace(w http.ResponseWriter, r *http.Request) {
+	h.limited(w, r, time.Second, pprof.Trace)
+}
+
+// Goroutines returns all goroutines grouped by stack, largest groups first
+func (h *ProfilingHandler) Goroutines(w http.ResponseWriter, r *http.Request) {
+	dump, err := profiling.DumpGoroutines()
+	if err != nil {
+		response.ErrorFromAppError(w, r, errors.InternalServerWithCause("GOROUTINE_DUMP_FAILED", "Goroutines could not be dumped", err))
+		return
+	}
+	response.Success(w, r, dump)
+}
+
+// limited rejects captures longer than maxDuration before handing over to
+// net/http/pprof, which reads the same seconds parameter
+func (h *ProfilingHandler) limited(w http.ResponseWriter, r *http.Request, fallback time.Duration, capture http.HandlerFunc) {
+	duration := fallback
+	if value := r.URL.Query().Get("seconds"); value != "" {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds <= 0 {
+			response.ErrorFromAppError(w, r, errors.BadRequest("INVALID_DURATION", "seconds must be a positive number"))
+			return
+		}
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+	if h.maxDuration > 0 && duration > h.maxDuration {
+		response.ErrorFromAppError(w, r, errors.BadRequest("INVALID_DURATION",
+			"seconds must not exceed "+strconv.Itoa(int(h.maxDuration.Seconds()))))
+		return
+	}
+
+	h.logger.WithContext(r.Context()).Info("Capturing profile",
+		logger.String("path", r.URL.Path),
+		logger.Duration("duration", duration),
+	)
+	capture(w, r)
+}
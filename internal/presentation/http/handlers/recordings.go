@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/recording"
+	"go-clean-template/internal/shared/errors"
+	"go-clean-template/internal/shared/response"
+)
+
+const defaultRecordingsLimit = 50
+
+type RecordingsHandler struct {
+	store  recording.Store
+	logger logger.Logger
+}
+
+func NewRecordingsHandler(store recording.Store, log logger.Logger) *RecordingsHandler {
+	return &RecordingsHandler{
+		store:  store,
+		logger: log,
+	}
+}
+
+// List returns recording summaries, most recent first
+func (h *RecordingsHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultRecordingsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			response.ErrorFromAppError(w, r, errors.BadRequest("INVALID_LIMIT", "limit must be a positive number"))
+			return
+		}
+		limit = parsed
+	}
+
+	summaries, err := h.store.List(limit)
+	if err != nil {
+		h.logger.WithContext(r.Context()).Error("Failed to list recordings", logger.Error(err))
+		response.ErrorFromAppError(w, r, errors.InternalServer("RECORDINGS_UNAVAILABLE", "Recordings could not be listed"))
+		return
+	}
+	response.Success(w, r, summaries)
+}
+
+// Get returns one recorded request/response pair for cmd/replay
+func (h *RecordingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	// Request IDs contain slashes, so the ID is the rest of the path
+	exchange, err := h.store.Get(chi.URLParam(r, "*"))
+	switch {
+	case stderrors.Is(err, recording.ErrNotFound):
+		response.ErrorFromAppError(w, r, errors.NotFound("RECORDING_NOT_FOUND", "No recording with this ID"))
+	case err != nil:
+		h.logger.WithContext(r.Context()).Error("Failed to read recording", logger.Error(err))
+		response.ErrorFromAppError(w, r, errors.InternalServer("RECORDINGS_UNAVAILABLE", "Recording could not be read"))
+	default:
+		response.Success(w, r, exchange)
+	}
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/infrastructure/accesslog"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// AccessLog writes every request to the access log, with no skip rules or
+// sampling. Mount it outside Recoverer so recovered panics are logged with
+// their 500; a request whose handler aborted without a response is logged
+// as a 500 as well.
+func AccessLog(access *accesslog.Logger, log logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			completed := false
+
+			// Deferred so requests that abort with a panic are logged too
+			defer func() {
+				status := ww.Status()
+				switch {
+				case status != 0:
+				case completed:
+					status = http.StatusOK
+				default:
+					status = http.StatusInternalServerError
+				}
+
+				entry := newAccessLogEntry(r, start, status, int64(ww.BytesWritten()))
+				if err := access.Log(entry); err != nil {
+					log.OncePer("access_log_write", time.Minute).Error("Failed to write access log", logger.Error(err))
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+			completed = true
+		})
+	}
+}
+
+func newAccessLogEntry(r *http.Request, start time.Time, status int, bytesSent int64) *accesslog.Entry {
+	remoteAddr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+
+	query := logger.RedactQuery(r.URL.RawQuery)
+	uri := r.URL.EscapedPath()
+	if query != "" {
+		uri += "?" + query
+	}
+
+	bytesReceived := r.ContentLength
+	if bytesReceived < 0 {
+		bytesReceived = 0
+	}
+
+	return &accesslog.Entry{
+		Time:          start,
+		RemoteAddr:    remoteAddr,
+		User:          requestctx.UserID(r.Context()),
+		Method:        r.Method,
+		URI:           uri,
+		Path:          r.URL.Path,
+		Query:         query,
+		Proto:         r.Proto,
+		Host:          r.Host,
+		Status:        status,
+		BytesSent:     bytesSent,
+		BytesReceived: bytesReceived,
+		Duration:      time.Since(start),
+		RequestID:     middleware.GetReqID(r.Context()),
+		CorrelationID: requestctx.CorrelationID(r.Context()),
+		Header:        r.Header,
+	}
+}
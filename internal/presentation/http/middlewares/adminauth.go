@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"go-clean-template/internal/infrastructure/audit"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/requestctx"
+	"go-clean-template/internal/shared/response"
+)
+
+// adminActor identifies callers authenticated with the shared admin token
+const adminActor = "admin-token"
+
+// AdminAuth only lets requests through that present token as a Bearer
+// credential or come with a client certificate verified by the TLS
+// listener. Failed attempts are logged and audited with the client address.
+func AdminAuth(token string, recorder audit.Recorder, log logger.Logger) func(next http.Handler) http.Handler {
+	expected := []byte(token)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+				actor := "mtls:" + r.TLS.VerifiedChains[0][0].Subject.CommonName
+				next.ServeHTTP(w, r.WithContext(requestctx.WithUserID(r.Context(), actor)))
+				return
+			}
+
+			presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || len(expected) == 0 || subtle.ConstantTimeCompare([]byte(presented), expected) != 1 {
+				log.WithContext(r.Context()).Warn("Rejected admin request",
+					logger.String("path", r.URL.Path),
+					logger.String("remote_addr", r.RemoteAddr),
+				)
+				recordAudit(r, recorder, log, newAuditEvent(r, audit.ActionAuthFailure, audit.OutcomeDenied))
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "A valid admin token is required")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(requestctx.WithUserID(r.Context(), adminActor)))
+		})
+	}
+}
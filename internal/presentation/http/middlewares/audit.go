@@ -0,0 +1,74 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/infrastructure/audit"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// anonymousActor is recorded when no authenticated user is known
+const anonymousActor = "anonymous"
+
+// newAuditEvent fills the request details of an audit event
+func newAuditEvent(r *http.Request, action string, outcome audit.Outcome) audit.Event {
+	actor := requestctx.UserID(r.Context())
+	if actor == "" {
+		actor = anonymousActor
+	}
+
+	resource := r.Method + " " + r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		resource = r.Method + " " + rctx.RoutePattern()
+	}
+
+	return audit.Event{
+		Actor:         actor,
+		Action:        action,
+		Resource:      resource,
+		Outcome:       outcome,
+		IP:            extractClientIP(r),
+		RequestID:     middleware.GetReqID(r.Context()),
+		CorrelationID: requestctx.CorrelationID(r.Context()),
+	}
+}
+
+// recordAudit stores an event; failures are logged rather than failing the request
+func recordAudit(r *http.Request, recorder audit.Recorder, log logger.Logger, event audit.Event) {
+	if err := recorder.Record(r.Context(), event); err != nil {
+		log.WithContext(r.Context()).Error("Failed to record audit event",
+			logger.String("action", event.Action),
+			logger.Error(err),
+		)
+	}
+}
+
+// AuditAdmin records every call to the routes it wraps once the response
+// status is known. Mount it after AdminAuth so only authorised calls are
+// recorded here; rejected ones are recorded as auth failures.
+func AuditAdmin(recorder audit.Recorder, log logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			outcome := audit.OutcomeSuccess
+			if status >= http.StatusBadRequest {
+				outcome = audit.OutcomeFailure
+			}
+
+			event := newAuditEvent(r, audit.ActionAdminCall, outcome)
+			event.Details = map[string]string{"status": strconv.Itoa(status)}
+			recordAudit(r, recorder, log, event)
+		})
+	}
+}
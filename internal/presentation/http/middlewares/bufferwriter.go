@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// streamingContentTypes are never buffered so events reach the client as
+// they are written
+var streamingContentTypes = map[string]bool{
+	"text/event-stream":    true,
+	"application/x-ndjson": true,
+}
+
+func isStreamingContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	}
+	return streamingContentTypes[strings.ToLower(mediaType)]
+}
+
+// bufferWriter holds the status and body of a response so middleware can
+// inspect or rewrite it before anything reaches the client. Streaming
+// responses, and any response that is explicitly flushed, switch to pass
+// through mode and are written directly.
+type bufferWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	body        bytes.Buffer
+	wroteHeader bool
+	passthrough bool
+}
+
+func newBufferWriter(w http.ResponseWriter) *bufferWriter {
+	return &bufferWriter{ResponseWriter: w}
+}
+
+func (bw *bufferWriter) WriteHeader(code int) {
+	if bw.wroteHeader {
+		return
+	}
+	bw.wroteHeader = true
+	bw.statusCode = code
+
+	if isStreamingContentType(bw.Header().Get("Content-Type")) {
+		bw.passthrough = true
+		bw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (bw *bufferWriter) Write(p []byte) (int, error) {
+	if !bw.wroteHeader {
+		bw.WriteHeader(http.StatusOK)
+	}
+	if bw.passthrough {
+		return bw.ResponseWriter.Write(p)
+	}
+	return bw.body.Write(p)
+}
+
+// Flush commits whatever was buffered and streams the rest of the response
+func (bw *bufferWriter) Flush() {
+	if !bw.passthrough {
+		if !bw.wroteHeader {
+			bw.WriteHeader(http.StatusOK)
+		}
+		if !bw.passthrough {
+			bw.passthrough = true
+			bw.ResponseWriter.WriteHeader(bw.statusCode)
+			_, _ = bw.ResponseWriter.Write(bw.body.Bytes())
+			bw.body.Reset()
+		}
+	}
+	if flusher, ok := bw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (bw *bufferWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := bw.ResponseWriter.(http.Hijacker); ok {
+		bw.passthrough = true
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+func (bw *bufferWriter) Unwrap() http.ResponseWriter {
+	return bw.ResponseWriter
+}
+
+func (bw *bufferWriter) status() int {
+	if bw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return bw.statusCode
+}
+
+// buffered reports whether the response is still held in memory
+func (bw *bufferWriter) buffered() bool {
+	return !bw.passthrough
+}
+
+// commit writes the buffered response to the client
+func (bw *bufferWriter) commit() {
+	if bw.passthrough {
+		return
+	}
+	bw.passthrough = true
+	if !bw.wroteHeader {
+		// The handler wrote nothing; let net/http send its implicit 200
+		return
+	}
+	bw.ResponseWriter.WriteHeader(bw.statusCode)
+	_, _ = bw.ResponseWriter.Write(bw.body.Bytes())
+}
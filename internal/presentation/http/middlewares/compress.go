@@ -0,0 +1,378 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+const (
+	encodingGzip     = "gzip"
+	encodingDeflate  = "deflate"
+	encodingZstd     = "zstd"
+	encodingBrotli   = "br"
+	encodingIdentity = "identity"
+
+	defaultCompressionMinSize = 1024
+)
+
+var (
+	// defaultCompressionEncodings is the server preference order used to
+	// break ties between encodings the client accepts with equal quality
+	defaultCompressionEncodings = []string{encodingZstd, encodingBrotli, encodingGzip, encodingDeflate}
+
+	defaultCompressibleTypes = []string{
+		"application/json",
+		"application/xml",
+		"application/javascript",
+		"application/x-ndjson",
+		"application/problem+json",
+		"image/svg+xml",
+		"text/*",
+	}
+)
+
+// encoder is the common interface of the supported compressors
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// encoderPools reuse compressors, which are expensive to allocate
+var encoderPools = map[string]*sync.Pool{
+	encodingGzip: {New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	}},
+	encodingDeflate: {New: func() interface{} {
+		fw, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return fw
+	}},
+	encodingZstd: {New: func() interface{} {
+		zw, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return zw
+	}},
+	encodingBrotli: {New: func() interface{} {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}},
+}
+
+func acquireEncoder(encoding string, w io.Writer) encoder {
+	switch enc := encoderPools[encoding].Get().(type) {
+	case *gzip.Writer:
+		enc.Reset(w)
+		return enc
+	case *flate.Writer:
+		enc.Reset(w)
+		return enc
+	case *zstd.Encoder:
+		enc.Reset(w)
+		return enc
+	case *brotli.Writer:
+		enc.Reset(w)
+		return enc
+	default:
+		return nil
+	}
+}
+
+func releaseEncoder(encoding string, enc encoder) {
+	encoderPools[encoding].Put(enc)
+}
+
+// Compress negotiates a Content-Encoding from Accept-Encoding q-values and
+// compresses responses whose type is on the allowlist once they reach the
+// minimum size. Streaming responses are compressed as they are flushed.
+// Must run inside RequestLogger so the logged byte counts cover both the
+// uncompressed and the on-the-wire size.
+func Compress(cfg config.CompressionConfig) func(next http.Handler) http.Handler {
+	encodings := supportedEncodings(cfg.Encodings)
+	contentTypes := cfg.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressibleTypes
+	}
+	minSize := cfg.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				contentTypes:   contentTypes,
+				minSize:        minSize,
+				metrics:        responseMetricsFromContext(r.Context()),
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+func supportedEncodings(configured []string) []string {
+	if len(configured) == 0 {
+		return defaultCompressionEncodings
+	}
+
+	var encodings []string
+	for _, encoding := range configured {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if _, ok := encoderPools[encoding]; ok {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings
+}
+
+// negotiateEncoding returns the accepted encoding with the highest q-value,
+// preferring earlier server encodings on ties, or "" for identity
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		segments := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(segments[0]))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range segments[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.TrimSpace(key) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if coding == "*" {
+			wildcard = quality
+			continue
+		}
+		qualities[coding] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			if wildcard < 0 {
+				continue
+			}
+			quality = wildcard
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+
+	// Identity is preferred when the client ranks it strictly higher
+	if identity, ok := qualities[encodingIdentity]; ok && identity > bestQuality {
+		return ""
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it can decide
+// whether compressing it is worthwhile, then either compresses or passes
+// the remaining writes straight through
+type compressWriter struct {
+	http.ResponseWriter
+	encoding     string
+	contentTypes []string
+	minSize      int
+	metrics      *responseMetrics
+
+	statusCode  int
+	wroteHeader bool
+	decided     bool
+	compressing bool
+	pending     bytes.Buffer
+	enc         encoder
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.statusCode = code
+
+	// Bodiless and informational responses are never compressed
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.metrics != nil {
+		cw.metrics.uncompressedBytes += int64(len(p))
+	}
+
+	if !cw.decided {
+		if !cw.eligible() {
+			cw.decide(false)
+		} else if isStreamingContentType(cw.Header().Get("Content-Type")) {
+			cw.decide(true)
+		} else if length, err := strconv.Atoi(cw.Header().Get("Content-Length")); err == nil && length < cw.minSize {
+			cw.decide(false)
+		} else {
+			cw.pending.Write(p)
+			if cw.pending.Len() >= cw.minSize {
+				cw.decide(true)
+			}
+			return len(p), nil
+		}
+	}
+
+	if cw.compressing {
+		return cw.enc.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush pushes compressed data to the client so SSE and NDJSON streams stay live
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		cw.decide(cw.eligible())
+	}
+	if cw.compressing {
+		_ = cw.enc.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		cw.decided = true
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// eligible reports whether the response may be compressed at all
+func (cw *compressWriter) eligible() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" || strings.Contains(header.Get("Cache-Control"), "no-transform") {
+		return false
+	}
+	return isCompressibleType(header.Get("Content-Type"), cw.contentTypes)
+}
+
+// decide commits the headers and flushes anything buffered so far
+func (cw *compressWriter) decide(compress bool) {
+	if cw.decided {
+		return
+	}
+	cw.decided = true
+	cw.compressing = compress
+
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		// The representation changed, so a strong validator no longer applies
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		if cw.metrics != nil {
+			cw.metrics.contentEncoding = cw.encoding
+		}
+		cw.enc = acquireEncoder(cw.encoding, cw.ResponseWriter)
+	}
+
+	if cw.wroteHeader {
+		cw.ResponseWriter.WriteHeader(cw.statusCode)
+	}
+
+	if cw.pending.Len() > 0 {
+		if compress {
+			_, _ = cw.enc.Write(cw.pending.Bytes())
+		} else {
+			_, _ = cw.ResponseWriter.Write(cw.pending.Bytes())
+		}
+		cw.pending.Reset()
+	}
+}
+
+// close finishes the response: small bodies go out uncompressed and the
+// encoder trailer is written for compressed ones
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			// Nothing was written; leave the implicit response to net/http
+			cw.decided = true
+			return
+		}
+		cw.decide(false)
+	}
+
+	if cw.compressing && cw.enc != nil {
+		_ = cw.enc.Close()
+		releaseEncoder(cw.encoding, cw.enc)
+		cw.enc = nil
+	}
+}
+
+func isCompressibleType(contentType string, allowed []string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(candidate)
+		if candidate == mediaType {
+			return true
+		}
+		if strings.HasSuffix(candidate, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(candidate, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		want           string
+	}{
+		{name: "absent", acceptEncoding: "", want: ""},
+		{name: "single", acceptEncoding: "gzip", want: encodingGzip},
+		{name: "server order breaks ties", acceptEncoding: "gzip, br, zstd", want: encodingZstd},
+		{name: "higher q-value wins", acceptEncoding: "zstd;q=0.5, gzip;q=0.8", want: encodingGzip},
+		{name: "q=0 excludes", acceptEncoding: "zstd;q=0, br;q=0, gzip", want: encodingGzip},
+		{name: "wildcard", acceptEncoding: "*", want: encodingZstd},
+		{name: "wildcard does not override explicit", acceptEncoding: "zstd;q=0, br;q=0, *;q=0.5", want: encodingGzip},
+		{name: "identity ranked higher", acceptEncoding: "identity, gzip;q=0.5", want: ""},
+		{name: "unknown only", acceptEncoding: "compress", want: ""},
+		{name: "case and spacing", acceptEncoding: " GZIP ; q=1 ", want: encodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negotiateEncoding(tt.acceptEncoding, defaultCompressionEncodings); got != tt.want {
+				t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+			}
+		})
+	}
+}
+
+// decompress undoes encoding so tests can compare bodies
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var (
+		reader io.Reader
+		err    error
+	)
+	switch encoding {
+	case "":
+		return string(body)
+	case encodingGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case encodingDeflate:
+		reader = flate.NewReader(bytes.NewReader(body))
+	case encodingZstd:
+		var zr *zstd.Decoder
+		zr, err = zstd.NewReader(bytes.NewReader(body))
+		if err == nil {
+			defer zr.Close()
+		}
+		reader = zr
+	case encodingBrotli:
+		reader = brotli.NewReader(bytes.NewReader(body))
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+	if err != nil {
+		t.Fatalf("%s reader: %v", encoding, err)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("%s decode: %v", encoding, err)
+	}
+	return string(decoded)
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"id":1,"name":"item"}`, 100)
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		header         map[string]string
+		status         int
+		body           string
+		wantEncoding   string
+		wantETag       string
+	}{
+		{name: "gzip", acceptEncoding: "gzip", body: large, wantEncoding: encodingGzip},
+		{name: "deflate", acceptEncoding: "deflate", body: large, wantEncoding: encodingDeflate},
+		{name: "zstd", acceptEncoding: "zstd", body: large, wantEncoding: encodingZstd},
+		{name: "brotli", acceptEncoding: "br", body: large, wantEncoding: encodingBrotli},
+		{name: "below the minimum size", acceptEncoding: "gzip", body: `{"id":1}`},
+		{name: "small Content-Length", acceptEncoding: "gzip", header: map[string]string{"Content-Length": "8"}, body: `{"id":1}`},
+		{name: "type not on the allowlist", acceptEncoding: "gzip", header: map[string]string{"Content-Type": "image/png"}, body: large},
+		{name: "already encoded", acceptEncoding: "gzip", header: map[string]string{"Content-Encoding": "identity"}, body: large},
+		{name: "no-transform", acceptEncoding: "gzip", header: map[string]string{"Cache-Control": "no-transform"}, body: large},
+		{name: "no Accept-Encoding", body: large},
+		{name: "HEAD", method: http.MethodHead, acceptEncoding: "gzip"},
+		{name: "no content", acceptEncoding: "gzip", status: http.StatusNoContent},
+		{name: "strong ETag weakened", acceptEncoding: "gzip", header: map[string]string{"ETag": `"v1"`}, body: large, wantEncoding: encodingGzip, wantETag: `W/"v1"`},
+		{name: "strong ETag kept uncompressed", acceptEncoding: "gzip", header: map[string]string{"ETag": `"v1"`}, body: `{"id":1}`, wantETag: `"v1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress(config.CompressionConfig{Enabled: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				for name, value := range tt.header {
+					w.Header().Set(name, value)
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				_, _ = io.WriteString(w, tt.body)
+			}))
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/api/v1/items", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			gotEncoding := rec.Header().Get("Content-Encoding")
+			if gotEncoding == "identity" {
+				gotEncoding = ""
+			}
+			if gotEncoding != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", gotEncoding, tt.wantEncoding)
+			}
+			if got := decompress(t, gotEncoding, rec.Body.Bytes()); got != tt.body {
+				t.Errorf("body differs after decoding (%d bytes, want %d)", len(got), len(tt.body))
+			}
+			if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding" {
+				t.Errorf("Vary = %v, want Accept-Encoding", got)
+			}
+			if tt.wantEncoding != "" && rec.Header().Get("Content-Length") != "" {
+				t.Error("Content-Length kept on a compressed response")
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentType  string
+		wantEncoding string
+	}{
+		{name: "compressible stream", contentType: "application/x-ndjson", wantEncoding: encodingGzip},
+		{name: "event stream", contentType: "text/event-stream", wantEncoding: encodingGzip},
+		{name: "binary stream", contentType: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := "{\"seq\":1}\n"
+			flushed := make(chan struct{})
+			resume := make(chan struct{})
+			handler := Compress(config.CompressionConfig{Enabled: true, Encodings: []string{encodingGzip}})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", tt.contentType)
+					_, _ = io.WriteString(w, first)
+					w.(http.Flusher).Flush()
+					close(flushed)
+					<-resume
+					_, _ = io.WriteString(w, "{\"seq\":2}\n")
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/stream", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				handler.ServeHTTP(rec, req)
+			}()
+			<-flushed
+
+			// The first event must be readable before the handler returns
+			if !rec.Flushed {
+				t.Error("Flush did not reach the underlying writer")
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			var partial io.Reader = bytes.NewReader(rec.Body.Bytes())
+			if tt.wantEncoding != "" {
+				gz, err := gzip.NewReader(partial)
+				if err != nil {
+					t.Fatalf("flushed bytes are not a gzip stream: %v", err)
+				}
+				partial = gz
+			}
+			got := make([]byte, len(first))
+			if _, err := io.ReadFull(partial, got); err != nil || string(got) != first {
+				t.Errorf("flushed %q (%v), want %q", got, err, first)
+			}
+
+			close(resume)
+			<-done
+			if got := decompress(t, tt.wantEncoding, rec.Body.Bytes()); got != first+"{\"seq\":2}\n" {
+				t.Errorf("full body = %q", got)
+			}
+		})
+	}
+}
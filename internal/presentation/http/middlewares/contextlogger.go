@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// ContextLogger stores a child of log in the request context, enriched with
+// the request ID, client IP, trace IDs, authenticated user and matched route,
+// so handlers and services get it through logger.FromContext. It must run
+// after RequestID, CorrelationID and Tracing.
+func ContextLogger(log logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			fields := []logger.Field{
+				logger.String("request_id", middleware.GetReqID(ctx)),
+				logger.String("client_ip", extractClientIP(r)),
+			}
+			if userID := requestctx.UserID(ctx); userID != "" {
+				fields = append(fields, logger.String("user_id", userID))
+			}
+
+			requestLog := log.WithContext(ctx).With(fields...)
+
+			// The route pattern is only complete once chi has finished routing,
+			// so it is resolved on the first log call made by the handler
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				requestLog = requestLog.WithLazy(logger.Stringer("route", routePattern{rctx}))
+			}
+
+			next.ServeHTTP(w, r.WithContext(logger.WithContext(ctx, requestLog)))
+		})
+	}
+}
+
+// routePattern renders the chi route matched so far
+type routePattern struct {
+	rctx *chi.Context
+}
+
+func (rp routePattern) String() string {
+	return rp.rctx.RoutePattern()
+}
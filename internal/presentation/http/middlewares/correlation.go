@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// maxCorrelationIDLength bounds client supplied IDs before they reach logs and headers
+const maxCorrelationIDLength = 128
+
+// CorrelationID accepts a correlation ID from any of the supported request
+// headers or generates one, stores it in the request context and returns it
+// in the X-Correlation-ID response header
+func CorrelationID() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := getHeaderValue(r, correlationHeaders, "")
+			if !isValidCorrelationID(id) {
+				id = requestctx.NewCorrelationID()
+			}
+
+			w.Header().Set(requestctx.CorrelationHeader, id)
+			next.ServeHTTP(w, r.WithContext(requestctx.WithCorrelationID(r.Context(), id)))
+		})
+	}
+}
+
+// isValidCorrelationID only accepts visible ASCII so IDs cannot inject log
+// lines or header values
+func isValidCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
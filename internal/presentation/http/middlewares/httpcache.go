@@ -0,0 +1,322 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"go-clean-template/internal/infrastructure/cache"
+	"go-clean-template/internal/infrastructure/logger"
+)
+
+// CachePolicy describes the Cache-Control header for a route
+type CachePolicy struct {
+	MaxAge               time.Duration
+	SharedMaxAge         time.Duration
+	StaleWhileRevalidate time.Duration
+	Public               bool
+	Private              bool
+	NoCache              bool
+	NoStore              bool
+	MustRevalidate       bool
+	Immutable            bool
+}
+
+// NoStorePolicy is used for responses that must never be reused, such as probes
+var NoStorePolicy = CachePolicy{NoStore: true}
+
+// String renders the policy as a Cache-Control header value
+func (p CachePolicy) String() string {
+	var directives []string
+
+	if p.NoStore {
+		return "no-store"
+	}
+	if p.Public {
+		directives = append(directives, "public")
+	}
+	if p.Private {
+		directives = append(directives, "private")
+	}
+	if p.NoCache {
+		directives = append(directives, "no-cache")
+	}
+	directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge.Seconds())))
+	if p.SharedMaxAge > 0 {
+		directives = append(directives, "s-maxage="+strconv.Itoa(int(p.SharedMaxAge.Seconds())))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+strconv.Itoa(int(p.StaleWhileRevalidate.Seconds())))
+	}
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+	if p.Immutable {
+		directives = append(directives, "immutable")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl sets the Cache-Control header for a route unless the handler
+// sets one itself
+func CacheControl(policy CachePolicy) func(next http.Handler) http.Handler {
+	value := policy.String()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if w.Header().Get("Cache-Control") == "" {
+				w.Header().Set("Cache-Control", value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ETag buffers successful GET and HEAD responses, tags them with a strong
+// ETag computed over the body and answers matching If-None-Match or
+// If-Modified-Since requests with 304 Not Modified. Streaming responses pass
+// through untouched.
+func ETag() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := newBufferWriter(w)
+			next.ServeHTTP(bw, r)
+
+			if !bw.buffered() || bw.status() != http.StatusOK {
+				bw.commit()
+				return
+			}
+
+			header := bw.Header()
+			etag := header.Get("ETag")
+			if etag == "" {
+				etag = strongETag(bw.body.Bytes())
+				header.Set("ETag", etag)
+			}
+
+			if isNotModified(r, etag, header.Get("Last-Modified")) {
+				writeNotModified(w)
+				return
+			}
+
+			bw.commit()
+		})
+	}
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// isNotModified evaluates conditional request headers per RFC 9110: when
+// If-None-Match is present If-Modified-Since is ignored
+func isNotModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatches uses the weak comparison required for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified sends a 304 keeping only the headers RFC 9110 allows
+func writeNotModified(w http.ResponseWriter) {
+	header := w.Header()
+	for _, name := range []string{"Content-Type", "Content-Length", "Content-Encoding"} {
+		header.Del(name)
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// ResponseCacheOptions configures the server-side GET response cache
+type ResponseCacheOptions struct {
+	TTL time.Duration
+	// VaryHeaders are request headers that select between cached variants
+	VaryHeaders []string
+}
+
+// cachedResponse is the stored form of a cached GET response
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// DefaultCacheVaryHeaders covers the negotiation headers used by
+// shared/response. Accept-Encoding is left out: ResponseCache sits inside
+// Compress, so it only ever stores identity bodies.
+var DefaultCacheVaryHeaders = []string{"Accept"}
+
+// ResponseCache serves GET responses from store, keyed by route pattern,
+// path, query and the configured Vary request headers. Only 200 responses
+// without Set-Cookie or a no-store/private Cache-Control are stored.
+// Requests sending Cache-Control: no-cache bypass the cached copy.
+func ResponseCache(store cache.Store, opts ResponseCacheOptions, log logger.Logger) func(next http.Handler) http.Handler {
+	if len(opts.VaryHeaders) == 0 {
+		opts.VaryHeaders = DefaultCacheVaryHeaders
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := responseCacheKey(r, opts.VaryHeaders)
+			ctx, cancel := context.WithTimeout(r.Context(), cacheStoreOpTimeout)
+			defer cancel()
+
+			if !strings.Contains(r.Header.Get("Cache-Control"), "no-cache") {
+				if cached, ok := loadCachedResponse(ctx, store, key, log); ok {
+					writeCachedResponse(w, cached)
+					return
+				}
+			}
+
+			bw := newBufferWriter(w)
+			next.ServeHTTP(bw, r)
+
+			if bw.buffered() && isCacheable(bw) {
+				storeCachedResponse(r.Context(), store, key, bw, opts.TTL, log)
+				bw.Header().Set("X-Cache", "MISS")
+			}
+			bw.commit()
+		})
+	}
+}
+
+const (
+	cacheStoreOpTimeout = time.Second
+	// cacheErrorLogInterval limits store failure warnings, which would
+	// otherwise be logged on every request while the store is down
+	cacheErrorLogInterval = time.Minute
+)
+
+func responseCacheKey(r *http.Request, varyHeaders []string) string {
+	var b strings.Builder
+
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		b.WriteString(rctx.RoutePattern())
+	}
+	b.WriteString("\n" + r.URL.Path + "\n")
+
+	// Sort the query so parameter order does not fragment the cache
+	query := r.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		b.WriteString(name + "=" + strings.Join(values, ",") + "&")
+	}
+
+	for _, name := range varyHeaders {
+		b.WriteString("\n" + strings.ToLower(name) + ":" + r.Header.Get(name))
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return "response-cache:" + hex.EncodeToString(sum[:])
+}
+
+func isCacheable(bw *bufferWriter) bool {
+	if bw.status() != http.StatusOK || bw.Header().Get("Set-Cookie") != "" {
+		return false
+	}
+	cacheControl := bw.Header().Get("Cache-Control")
+	return !strings.Contains(cacheControl, "no-store") && !strings.Contains(cacheControl, "private")
+}
+
+func loadCachedResponse(ctx context.Context, store cache.Store, key string, log logger.Logger) (cachedResponse, bool) {
+	var cached cachedResponse
+
+	payload, err := store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			log.OncePer("response-cache-read", cacheErrorLogInterval).Warn("Failed to read response cache", logger.Error(err))
+		}
+		return cached, false
+	}
+	if err := json.Unmarshal(payload, &cached); err != nil {
+		log.Warn("Discarding corrupt cached response", logger.Error(err))
+		return cached, false
+	}
+	return cached, true
+}
+
+func storeCachedResponse(ctx context.Context, store cache.Store, key string, bw *bufferWriter, ttl time.Duration, log logger.Logger) {
+	payload, err := json.Marshal(cachedResponse{
+		Status:   bw.status(),
+		Header:   replayableHeaders(bw.Header()),
+		Body:     bw.body.Bytes(),
+		StoredAt: time.Now(),
+	})
+	if err != nil {
+		log.Warn("Failed to encode cached response", logger.Error(err))
+		return
+	}
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheStoreOpTimeout)
+	defer cancel()
+	if err := store.Set(storeCtx, key, payload, ttl); err != nil {
+		log.OncePer("response-cache-write", cacheErrorLogInterval).Warn("Failed to write response cache", logger.Error(err))
+	}
+}
+
+func writeCachedResponse(w http.ResponseWriter, cached cachedResponse) {
+	for name, values := range cached.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("X-Cache", "HIT")
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+	w.Header().Set("Content-Length", strconv.Itoa(len(cached.Body)))
+	w.WriteHeader(cached.Status)
+	_, _ = w.Write(cached.Body)
+}
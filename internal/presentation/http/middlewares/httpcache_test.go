@@ -0,0 +1,134 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go-clean-template/internal/infrastructure/cache"
+	"go-clean-template/internal/infrastructure/logger"
+)
+
+func TestETag(t *testing.T) {
+	body := `{"id":1}`
+	etag := strongETag([]byte(body))
+	lastModified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		method     string
+		header     map[string]string
+		handlerTag string
+		wantStatus int
+		wantETag   string
+	}{
+		{name: "no condition", method: http.MethodGet, wantStatus: http.StatusOK, wantETag: etag},
+		{name: "matching If-None-Match", method: http.MethodGet, header: map[string]string{"If-None-Match": etag}, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "weak If-None-Match", method: http.MethodGet, header: map[string]string{"If-None-Match": `"other", W/` + etag}, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "wildcard If-None-Match", method: http.MethodHead, header: map[string]string{"If-None-Match": "*"}, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "stale If-None-Match", method: http.MethodGet, header: map[string]string{"If-None-Match": `"other"`}, wantStatus: http.StatusOK, wantETag: etag},
+		{name: "handler ETag", method: http.MethodGet, header: map[string]string{"If-None-Match": `"v7"`}, handlerTag: `"v7"`, wantStatus: http.StatusNotModified, wantETag: `"v7"`},
+		{
+			name:   "If-None-Match wins over If-Modified-Since",
+			method: http.MethodGet,
+			header: map[string]string{
+				"If-None-Match":     `"other"`,
+				"If-Modified-Since": lastModified.Format(http.TimeFormat),
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   etag,
+		},
+		{name: "not modified since", method: http.MethodGet, header: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "modified since", method: http.MethodGet, header: map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, wantStatus: http.StatusOK, wantETag: etag},
+		{name: "unsafe method", method: http.MethodPost, header: map[string]string{"If-None-Match": "*"}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ETag()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+				if tt.handlerTag != "" {
+					w.Header().Set("ETag", tt.handlerTag)
+				}
+				_, _ = io.WriteString(w, body)
+			}))
+
+			req := httptest.NewRequest(tt.method, "/api/v1/orders/1", nil)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				if rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" || rec.Header().Get("Content-Length") != "" {
+					t.Errorf("304 carries a body or representation headers: %v %q", rec.Header(), rec.Body.String())
+				}
+			} else if tt.method != http.MethodHead && rec.Body.String() != body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), body)
+			}
+		})
+	}
+}
+
+func TestResponseCacheVariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		second    map[string]string
+		wantCache string
+		wantCalls int32
+	}{
+		{name: "same request", second: map[string]string{"Accept": "application/json"}, wantCache: "HIT", wantCalls: 1},
+		{name: "other encoding", second: map[string]string{"Accept": "application/json", "Accept-Encoding": "gzip"}, wantCache: "HIT", wantCalls: 1},
+		{name: "other media type", second: map[string]string{"Accept": "application/xml"}, wantCache: "MISS", wantCalls: 2},
+		{name: "no-cache", second: map[string]string{"Accept": "application/json", "Cache-Control": "no-cache"}, wantCache: "MISS", wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := cache.NewMemoryStore()
+			t.Cleanup(func() { _ = store.Close() })
+
+			var calls atomic.Int32
+			handler := ResponseCache(store, ResponseCacheOptions{TTL: time.Minute}, logger.NewFromZap(zap.NewNop()))(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls.Add(1)
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = io.WriteString(w, `{"id":1}`)
+				}))
+
+			first := httptest.NewRequest(http.MethodGet, "/api/v1/orders?b=2&a=1", nil)
+			first.Header.Set("Accept", "application/json")
+			handler.ServeHTTP(httptest.NewRecorder(), first)
+
+			second := httptest.NewRequest(http.MethodGet, "/api/v1/orders?a=1&b=2", nil)
+			for name, value := range tt.second {
+				second.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, second)
+
+			if got := rec.Header().Get("X-Cache"); got != tt.wantCache {
+				t.Errorf("X-Cache = %q, want %q", got, tt.wantCache)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", got, tt.wantCalls)
+			}
+			if rec.Body.String() != `{"id":1}` {
+				t.Errorf("body = %q", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-clean-template/internal/infrastructure/config"
+)
+
+// httpLogRules is the compiled form of logging.http
+type httpLogRules struct {
+	skipPaths    map[string]bool
+	skipGlobs    []*regexp.Regexp
+	sampleRate   float64
+	slowWarn     time.Duration
+	slowError    time.Duration
+	maxBodyBytes int
+	routes       []httpLogRoute
+}
+
+type httpLogRoute struct {
+	path           *regexp.Regexp
+	methods        map[string]bool
+	sampleRate     float64
+	captureHeaders bool
+	captureBody    bool
+}
+
+// requestLogPlan is what RequestLogger does for one request
+type requestLogPlan struct {
+	skip           bool
+	sampleRate     float64
+	captureHeaders bool
+	captureBody    bool
+}
+
+func newHTTPLogRules(cfg config.HTTPLoggingConfig) (*httpLogRules, error) {
+	rules := &httpLogRules{
+		skipPaths:    make(map[string]bool, len(cfg.SkipPaths)),
+		sampleRate:   cfg.SampleRate,
+		slowWarn:     time.Duration(cfg.SlowWarnMs) * time.Millisecond,
+		slowError:    time.Duration(cfg.SlowErrorMs) * time.Millisecond,
+		maxBodyBytes: cfg.MaxBodyBytes,
+	}
+	for _, path := range cfg.SkipPaths {
+		rules.skipPaths[path] = true
+	}
+	for _, glob := range cfg.SkipGlobs {
+		re, err := compilePathGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		rules.skipGlobs = append(rules.skipGlobs, re)
+	}
+
+	for _, route := range cfg.Routes {
+		re, err := compilePathGlob(route.Path)
+		if err != nil {
+			return nil, err
+		}
+		compiled := httpLogRoute{
+			path:           re,
+			sampleRate:     route.SampleRate,
+			captureHeaders: route.CaptureHeaders,
+			captureBody:    route.CaptureBody,
+		}
+		if len(route.Methods) > 0 {
+			compiled.methods = make(map[string]bool, len(route.Methods))
+			for _, method := range route.Methods {
+				compiled.methods[strings.ToUpper(method)] = true
+			}
+		}
+		rules.routes = append(rules.routes, compiled)
+	}
+	return rules, nil
+}
+
+// compilePathGlob turns a path glob into an anchored expression: * and ?
+// stay within a segment, ** spans segments and "**/" may match nothing
+func compilePathGlob(glob string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid path glob %q: %w", glob, err)
+	}
+	return re, nil
+}
+
+// plan applies skip rules and the first matching route to r
+func (h *httpLogRules) plan(r *http.Request) requestLogPlan {
+	path := r.URL.Path
+	if h.skipPaths[path] {
+		return requestLogPlan{skip: true}
+	}
+	for _, glob := range h.skipGlobs {
+		if glob.MatchString(path) {
+			return requestLogPlan{skip: true}
+		}
+	}
+
+	plan := requestLogPlan{sampleRate: h.sampleRate}
+	for _, route := range h.routes {
+		if route.methods != nil && !route.methods[r.Method] {
+			continue
+		}
+		if !route.path.MatchString(path) {
+			continue
+		}
+		if route.sampleRate > 0 {
+			plan.sampleRate = route.sampleRate
+		}
+		plan.captureHeaders = route.captureHeaders
+		plan.captureBody = route.captureBody
+		break
+	}
+	return plan
+}
+
+// sampled reports whether a successful, fast request is logged
+func (p requestLogPlan) sampled() bool {
+	return p.sampleRate <= 0 || p.sampleRate >= 1 || rand.Float64() < p.sampleRate
+}
+
+// slowLevel returns the status that a request of this duration is logged
+// as, so slow requests raise the level chosen by logWithLevel
+func (h *httpLogRules) slowLevel(duration time.Duration) (int, bool) {
+	switch {
+	case h.slowError > 0 && duration >= h.slowError:
+		return http.StatusInternalServerError, true
+	case h.slowWarn > 0 && duration >= h.slowWarn:
+		return http.StatusBadRequest, true
+	default:
+		return 0, false
+	}
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the rest
+type cappedBuffer struct {
+	limit int
+	data  []byte
+	total int64
+}
+
+// Write always reports the whole of p as written, as io.Writer requires,
+// even when only part of it was kept
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if room := b.limit - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(len(p), room)]...)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) truncated() bool {
+	return b.total > int64(len(b.data))
+}
+
+// capturingBody copies what the handler reads from the request body
+type capturingBody struct {
+	io.ReadCloser
+	capture *cappedBuffer
+}
+
+func (c *capturingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = c.capture.Write(p[:n])
+	}
+	return n, err
+}
+
+// isTextualContentType reports whether a body can be logged as text
+func isTextualContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "":
+		return true
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-www-form-urlencoded", "application/x-ndjson":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,322 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-clean-template/internal/infrastructure/cache"
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/requestctx"
+	"go-clean-template/internal/shared/response"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyRecordPrefix   = "idempotency:record:"
+	idempotencyLockPrefix     = "idempotency:lock:"
+	idempotencyStoreOpTimeout = 2 * time.Second
+)
+
+// idempotencyRecord is the stored first response for a key
+type idempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// nonReplayableHeaders describe the original exchange rather than the
+// resource, so they are dropped from stored responses. Encoding headers are
+// among them because the stored body is captured before compression; outer
+// middlewares set them again on replay.
+var nonReplayableHeaders = map[string]bool{
+	"Date":                  true,
+	"Content-Length":        true,
+	"Content-Encoding":      true,
+	"Vary":                  true,
+	"Etag":                  true,
+	"Age":                   true,
+	"X-Cache":               true,
+	"X-Correlation-Id":      true,
+	"X-Request-Id":          true,
+	"Traceparent":           true,
+	"Tracestate":            true,
+	"Retry-After":           true,
+	"X-Ratelimit-Limit":     true,
+	"X-Ratelimit-Remaining": true,
+	"X-Ratelimit-Reset":     true,
+	"X-Ratelimit-Window":    true,
+}
+
+// Idempotency replays the first response recorded for an Idempotency-Key on
+// POST, PUT, PATCH and DELETE requests. Reusing a key for a different request
+// is rejected with 422 and a duplicate that arrives while the first request
+// is still running gets 409. Keys are scoped to the caller, method and path,
+// so different clients never see each other's responses. Server errors and
+// streamed responses are not stored so clients can retry.
+func Idempotency(cfg config.IdempotencyConfig, store cache.Store, log logger.Logger) func(next http.Handler) http.Handler {
+	ttl := time.Duration(cfg.TTL) * time.Second
+	lockTTL := time.Duration(cfg.LockTTL) * time.Second
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if !cfg.Enabled || key == "" || !isUnsafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				response.Error(w, r, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY",
+					"Idempotency-Key must not exceed "+strconv.Itoa(maxIdempotencyKeyLength)+" characters")
+				return
+			}
+
+			fingerprint, err := fingerprintRequest(r, cfg.MaxBodyBytes)
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					response.Error(w, r, http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE",
+						"Request body is too large for an idempotent request")
+					return
+				}
+				response.Error(w, r, http.StatusBadRequest, "INVALID_BODY", "Request body could not be read")
+				return
+			}
+
+			key = idempotencyStoreKey(r, key)
+
+			ctx := r.Context()
+			storeCtx, cancel := context.WithTimeout(ctx, idempotencyStoreOpTimeout)
+			defer cancel()
+
+			if record, found := loadIdempotencyRecord(storeCtx, store, key, log); found {
+				replayIdempotencyRecord(w, r, record, fingerprint)
+				return
+			}
+
+			acquired, err := store.SetNX(storeCtx, idempotencyLockPrefix+key, []byte(fingerprint), lockTTL)
+			if err != nil {
+				log.Error("Idempotency store unavailable", logger.Error(err))
+				response.Error(w, r, http.StatusServiceUnavailable, "IDEMPOTENCY_UNAVAILABLE",
+					"Idempotent requests cannot be processed right now")
+				return
+			}
+			if !acquired {
+				// The first request may have finished between the lookup and the lock
+				if record, found := loadIdempotencyRecord(storeCtx, store, key, log); found {
+					replayIdempotencyRecord(w, r, record, fingerprint)
+					return
+				}
+				if locked, err := store.Get(storeCtx, idempotencyLockPrefix+key); err == nil && string(locked) != fingerprint {
+					rejectIdempotencyMismatch(w, r)
+					return
+				}
+				response.Error(w, r, http.StatusConflict, "IDEMPOTENCY_REQUEST_IN_PROGRESS",
+					"A request with this Idempotency-Key is already being processed")
+				return
+			}
+
+			defer func() {
+				// Release the lock even if the client went away mid-request
+				unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyStoreOpTimeout)
+				defer cancel()
+				if err := store.Delete(unlockCtx, idempotencyLockPrefix+key); err != nil {
+					log.Warn("Failed to release idempotency lock", logger.Error(err))
+				}
+			}()
+
+			cw := &captureWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+
+			if cw.streamed || cw.status() >= http.StatusInternalServerError {
+				return
+			}
+
+			record := idempotencyRecord{
+				Fingerprint: fingerprint,
+				Status:      cw.status(),
+				Header:      replayableHeaders(cw.headerSnapshot()),
+				Body:        cw.body.Bytes(),
+			}
+			payload, err := json.Marshal(record)
+			if err != nil {
+				log.Error("Failed to encode idempotency record", logger.Error(err))
+				return
+			}
+
+			saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyStoreOpTimeout)
+			defer cancel()
+			if err := store.Set(saveCtx, idempotencyRecordPrefix+key, payload, ttl); err != nil {
+				log.Error("Failed to store idempotency record", logger.Error(err))
+			}
+		})
+	}
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// idempotencyStoreKey scopes the client supplied key to the authenticated
+// user, or the client IP for anonymous calls, and to the method and path
+func idempotencyStoreKey(r *http.Request, key string) string {
+	identity := requestctx.UserID(r.Context())
+	if identity == "" {
+		identity = "ip:" + getClientIP(r)
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(identity + "\n" + r.Method + "\n" + r.URL.Path + "\n" + key))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fingerprintRequest hashes method, URL and body and restores the body for the handler
+func fingerprintRequest(r *http.Request, maxBytes int64) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n"))
+
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+		_ = r.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		hash.Write(body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func loadIdempotencyRecord(ctx context.Context, store cache.Store, key string, log logger.Logger) (idempotencyRecord, bool) {
+	var record idempotencyRecord
+
+	payload, err := store.Get(ctx, idempotencyRecordPrefix+key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			log.Warn("Failed to load idempotency record", logger.Error(err))
+		}
+		return record, false
+	}
+
+	if err := json.Unmarshal(payload, &record); err != nil {
+		log.Warn("Discarding corrupt idempotency record", logger.Error(err))
+		return record, false
+	}
+	return record, true
+}
+
+func replayIdempotencyRecord(w http.ResponseWriter, r *http.Request, record idempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		rejectIdempotencyMismatch(w, r)
+		return
+	}
+
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.Header().Set("Content-Length", strconv.Itoa(len(record.Body)))
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+func rejectIdempotencyMismatch(w http.ResponseWriter, r *http.Request) {
+	response.Error(w, r, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_MISMATCH",
+		"Idempotency-Key was already used for a different request")
+}
+
+func replayableHeaders(header http.Header) http.Header {
+	filtered := make(http.Header, len(header))
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		// CORS headers depend on the Origin of each request
+		if nonReplayableHeaders[canonical] || strings.HasPrefix(canonical, "Access-Control-") {
+			continue
+		}
+		filtered[name] = append([]string(nil), values...)
+	}
+	return filtered
+}
+
+// captureWriter passes the response through while keeping a copy of it.
+// The header map is shared with outer writers such as Compress, which change
+// it once the status is written, so the headers set by the handler are
+// copied at that point. Flushing marks the response as streamed, which is
+// never stored.
+type captureWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+	streamed    bool
+}
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.statusCode = code
+		cw.header = cw.Header().Clone()
+		cw.wroteHeader = true
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+// headerSnapshot returns the headers as they were when the status was written
+func (cw *captureWriter) headerSnapshot() http.Header {
+	if cw.header == nil {
+		return cw.Header().Clone()
+	}
+	return cw.header
+}
+
+func (cw *captureWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.streamed {
+		cw.body.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush lets NDJSON and SSE handlers stream through the middleware
+func (cw *captureWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	cw.streamed = true
+	cw.body.Reset()
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (cw *captureWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *captureWriter) status() int {
+	if cw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return cw.statusCode
+}
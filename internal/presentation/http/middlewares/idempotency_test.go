@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"go-clean-template/internal/infrastructure/cache"
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+)
+
+// newIdempotencyTestHandler mounts Idempotency inside Compress as the router
+// does and counts how often the wrapped handler runs
+func newIdempotencyTestHandler(t *testing.T, handler http.HandlerFunc) (http.Handler, *atomic.Int32) {
+	t.Helper()
+
+	store := cache.NewMemoryStore()
+	t.Cleanup(func() { _ = store.Close() })
+
+	calls := new(atomic.Int32)
+	counted := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler(w, r)
+	})
+
+	idempotency := Idempotency(config.IdempotencyConfig{
+		Enabled:      true,
+		TTL:          60,
+		LockTTL:      10,
+		MaxBodyBytes: 1 << 20,
+	}, store, logger.NewFromZap(zap.NewNop()))
+	compress := Compress(config.CompressionConfig{Enabled: true, Encodings: []string{"gzip"}})
+
+	return compress(idempotency(counted)), calls
+}
+
+func idempotentRequest(key, body, remoteAddr string, gzipped bool) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, key)
+	if gzipped {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if remoteAddr != "" {
+		req.RemoteAddr = remoteAddr
+	}
+	return req
+}
+
+// decodedBody returns the response body, gunzipping it when it is encoded
+func decodedBody(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		return rec.Body.String()
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("response claims gzip but is not: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to gunzip response: %v", err)
+	}
+	return string(body)
+}
+
+func TestIdempotencyReplayThroughCompress(t *testing.T) {
+	payload := `{"x":"` + strings.Repeat("a", 4096) + `"}`
+
+	tests := []struct {
+		name         string
+		firstGzip    bool
+		replayGzip   bool
+		wantEncoding string
+	}{
+		{name: "gzip then gzip", firstGzip: true, replayGzip: true, wantEncoding: "gzip"},
+		{name: "gzip then identity", firstGzip: true, replayGzip: false, wantEncoding: ""},
+		{name: "identity then gzip", firstGzip: false, replayGzip: true, wantEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, calls := newIdempotencyTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Location", "/api/v1/orders/1")
+				w.Header().Set("ETag", `"v1"`)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = io.WriteString(w, payload)
+			})
+
+			first := httptest.NewRecorder()
+			handler.ServeHTTP(first, idempotentRequest("key-1", `{"a":1}`, "", tt.firstGzip))
+			if first.Code != http.StatusCreated {
+				t.Fatalf("first request: status %d", first.Code)
+			}
+
+			replay := httptest.NewRecorder()
+			handler.ServeHTTP(replay, idempotentRequest("key-1", `{"a":1}`, "", tt.replayGzip))
+
+			if calls.Load() != 1 {
+				t.Fatalf("handler ran %d times, want 1", calls.Load())
+			}
+			if replay.Code != http.StatusCreated || replay.Header().Get(idempotentReplayedHeader) != "true" {
+				t.Fatalf("expected a replayed 201, got %d %v", replay.Code, replay.Header())
+			}
+			if got := replay.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := decodedBody(t, replay); got != payload {
+				t.Errorf("replayed body does not match the original (%d bytes)", len(got))
+			}
+			if got := replay.Header().Values("Vary"); len(got) != 1 {
+				t.Errorf("Vary = %v, want a single Accept-Encoding", got)
+			}
+			if got := replay.Header().Get("Location"); got != "/api/v1/orders/1" {
+				t.Errorf("Location = %q, want the stored value", got)
+			}
+			if got := replay.Header().Get("ETag"); got != "" && !strings.HasPrefix(got, "W/") && tt.wantEncoding != "" {
+				t.Errorf("stored ETag %q was replayed on a compressed response", got)
+			}
+		})
+	}
+}
+
+func TestIdempotencyRejections(t *testing.T) {
+	tests := []struct {
+		name       string
+		second     *http.Request
+		wantStatus int
+		wantCode   string
+		wantCalls  int32
+	}{
+		{
+			name:       "same key with a different body",
+			second:     idempotentRequest("key-1", `{"a":2}`, "", false),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "IDEMPOTENCY_KEY_MISMATCH",
+			wantCalls:  1,
+		},
+		{
+			name:       "same key from another client",
+			second:     idempotentRequest("key-1", `{"a":1}`, "198.51.100.7:4000", false),
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, calls := newIdempotencyTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, `{"ok":true}`)
+			})
+
+			handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", `{"a":1}`, "", true))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.second)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), tt.wantCode) {
+				t.Errorf("body %s does not contain %s", rec.Body.String(), tt.wantCode)
+			}
+			if rec.Header().Get(idempotentReplayedHeader) != "" {
+				t.Error("response was replayed")
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler, calls := newIdempotencyTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", `{"a":1}`, "", true))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("key-1", `{"a":1}`, "", true))
+	close(release)
+	<-done
+
+	if rec.Code != http.StatusConflict || !strings.Contains(decodedBody(t, rec), "IDEMPOTENCY_REQUEST_IN_PROGRESS") {
+		t.Fatalf("expected 409 while the first request runs, got %d %s", rec.Code, decodedBody(t, rec))
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyStreamingIsNotStored(t *testing.T) {
+	handler, calls := newIdempotencyTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(w, "{}\n")
+		w.(http.Flusher).Flush()
+	})
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, idempotentRequest("key-1", `{"a":1}`, "", false))
+		if rec.Header().Get(idempotentReplayedHeader) != "" {
+			t.Fatal("streamed response was replayed")
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2", calls.Load())
+	}
+}
@@ -1,29 +1,20 @@
 package middlewares
 
 import (
+	"context"
+	"fmt"
 	"net/http"
-	"runtime/debug"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 
+	"go-clean-template/internal/infrastructure/config"
 	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/shared/requestctx"
 )
 
-// Skip patterns for reducing log noise
 var (
-	skipPaths = map[string]bool{
-		"/health":      true,
-		"/healthz":     true,
-		"/ping":        true,
-		"/metrics":     true,
-		"/favicon.ico": true,
-	}
-
-	skipPrefixes = []string{"/static/", "/assets/"}
-	skipSuffixes = []string{".css", ".js", ".ico"}
-
 	correlationHeaders = []string{
 		"X-Correlation-ID", "X-Correlation-Id",
 		"X-Request-ID", "X-Request-Id",
@@ -33,10 +24,20 @@ var (
 	ipHeaders = []string{"X-Forwarded-For", "X-Real-IP", "CF-Connecting-IP"}
 )
 
-func RequestLogger(log logger.Logger) func(next http.Handler) http.Handler {
+// RequestLogger logs one entry per request once the response is written.
+// logging.http decides which requests are skipped or sampled and which
+// routes also record headers and bodies; errors and slow requests are
+// always logged, slow ones at a raised level.
+func RequestLogger(cfg config.HTTPLoggingConfig, log logger.Logger) func(next http.Handler) http.Handler {
+	rules, err := newHTTPLogRules(cfg)
+	if err != nil {
+		log.Fatal("Invalid request logging configuration", logger.Error(err))
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if shouldSkipLogging(r.URL.Path) {
+			plan := rules.plan(r)
+			if plan.skip {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -44,42 +45,72 @@ func RequestLogger(log logger.Logger) func(next http.Handler) http.Handler {
 			start := time.Now()
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 			ctx := extractRequestContext(r)
+			metrics := &responseMetrics{}
+			req := withResponseMetrics(r, metrics)
+
+			var requestBody, responseBody *cappedBuffer
+			if plan.captureBody && rules.maxBodyBytes > 0 {
+				requestBody = &cappedBuffer{limit: rules.maxBodyBytes}
+				responseBody = &cappedBuffer{limit: rules.maxBodyBytes}
+				if req.Body != nil && req.Body != http.NoBody {
+					req.Body = &capturingBody{ReadCloser: req.Body, capture: requestBody}
+				}
+				ww.Tee(responseBody)
+			}
 
-			next.ServeHTTP(ww, r)
+			next.ServeHTTP(ww, req)
+			duration := time.Since(start)
+
+			levelStatus := ww.Status()
+			slowStatus, slow := rules.slowLevel(duration)
+			if slow && slowStatus > levelStatus {
+				levelStatus = slowStatus
+			}
+			if levelStatus < http.StatusBadRequest && !plan.sampled() {
+				return
+			}
 
 			// Build log fields with metrics
-			fields := buildRequestFields(r, ctx, ww, time.Since(start))
+			fields := buildRequestFields(r, ctx, ww, metrics, duration)
+			if slow {
+				fields = append(fields, logger.Bool("slow", true))
+			}
+			if plan.captureHeaders {
+				fields = append(fields,
+					logger.Any("request_headers", logger.RedactHeaders(r.Header)),
+					logger.Any("response_headers", logger.RedactHeaders(ww.Header())),
+				)
+			}
+			if requestBody != nil {
+				fields = append(fields, bodyFields("request_body", r.Header.Get("Content-Type"), "", requestBody)...)
+				fields = append(fields, bodyFields("response_body", ww.Header().Get("Content-Type"), metrics.contentEncoding, responseBody)...)
+			}
 
 			// Log with appropriate level based on status
-			logWithLevel(log, ww.Status(), "HTTP Request", fields...)
+			logWithLevel(log.WithContext(r.Context()), levelStatus, "HTTP Request", fields...)
 		})
 	}
 }
 
-func Recoverer(log logger.Logger) func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			defer func() {
-				if rvr := recover(); rvr != nil {
-					ctx := extractRequestContext(r)
-					fields := buildPanicFields(r, ctx, rvr)
-
-					log.Error("Panic recovered - Critical Error", fields...)
-
-					if !isResponseWritten(w) {
-						w.Header().Set("Content-Type", "application/json")
-						w.WriteHeader(http.StatusInternalServerError)
-						_, _ = w.Write([]byte(`{"error":"Internal Server Error","message":"An unexpected error occurred"}`))
-					}
-				}
-			}()
+// Helper functions for logging middleware
 
-			next.ServeHTTP(w, r)
-		})
-	}
+// responseMetrics collects figures about the response from middleware
+// running inside RequestLogger, such as Compress
+type responseMetrics struct {
+	uncompressedBytes int64
+	contentEncoding   string
 }
 
-// Helper functions for logging middleware
+type responseMetricsKey struct{}
+
+func withResponseMetrics(r *http.Request, metrics *responseMetrics) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), responseMetricsKey{}, metrics))
+}
+
+func responseMetricsFromContext(ctx context.Context) *responseMetrics {
+	metrics, _ := ctx.Value(responseMetricsKey{}).(*responseMetrics)
+	return metrics
+}
 
 type requestContext struct {
 	correlationID string
@@ -87,29 +118,14 @@ type requestContext struct {
 	clientIP      string
 }
 
-func shouldSkipLogging(path string) bool {
-	if skipPaths[path] {
-		return true
-	}
-
-	for _, prefix := range skipPrefixes {
-		if strings.HasPrefix(path, prefix) {
-			return true
-		}
-	}
-
-	for _, suffix := range skipSuffixes {
-		if strings.HasSuffix(path, suffix) {
-			return true
-		}
+func extractRequestContext(r *http.Request) requestContext {
+	correlationID := requestctx.CorrelationID(r.Context())
+	if correlationID == "" {
+		correlationID = getHeaderValue(r, correlationHeaders, middleware.GetReqID(r.Context()))
 	}
 
-	return false
-}
-
-func extractRequestContext(r *http.Request) requestContext {
 	return requestContext{
-		correlationID: getHeaderValue(r, correlationHeaders, middleware.GetReqID(r.Context())),
+		correlationID: correlationID,
 		requestID:     middleware.GetReqID(r.Context()),
 		clientIP:      extractClientIP(r),
 	}
@@ -145,7 +161,7 @@ func extractClientIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
-func buildRequestFields(r *http.Request, ctx requestContext, ww middleware.WrapResponseWriter, duration time.Duration) []logger.Field {
+func buildRequestFields(r *http.Request, ctx requestContext, ww middleware.WrapResponseWriter, metrics *responseMetrics, duration time.Duration) []logger.Field {
 	fields := []logger.Field{
 		logger.String("method", r.Method),
 		logger.String("path", r.URL.Path),
@@ -159,29 +175,64 @@ func buildRequestFields(r *http.Request, ctx requestContext, ww middleware.WrapR
 	}
 
 	if r.URL.RawQuery != "" {
-		fields = append(fields, logger.String("query", r.URL.RawQuery))
+		fields = append(fields, logger.String("query", logger.RedactQuery(r.URL.RawQuery)))
 	}
 
 	if r.ContentLength > 0 {
 		fields = append(fields, logger.Int64("request_size_bytes", r.ContentLength))
 	}
 
+	// response_size_bytes is what went over the wire; report the size before
+	// compression alongside it when an encoding was applied
+	if metrics.contentEncoding != "" {
+		fields = append(fields,
+			logger.String("content_encoding", metrics.contentEncoding),
+			logger.Int64("response_uncompressed_bytes", metrics.uncompressedBytes),
+		)
+	}
+
 	return fields
 }
 
-func buildPanicFields(r *http.Request, ctx requestContext, panicValue interface{}) []logger.Field {
+func buildPanicFields(r *http.Request, ctx requestContext, panicValue interface{}, stack []byte) []logger.Field {
 	return []logger.Field{
 		logger.String("method", r.Method),
 		logger.String("path", r.URL.Path),
+		logger.String("query", logger.RedactQuery(r.URL.RawQuery)),
+		logger.Any("headers", logger.RedactHeaders(r.Header)),
 		logger.String("correlation_id", ctx.correlationID),
 		logger.String("request_id", ctx.requestID),
 		logger.String("client_ip", ctx.clientIP),
 		logger.String("user_agent", r.UserAgent()),
 		logger.Any("panic_value", panicValue),
-		logger.String("stack_trace", string(debug.Stack())),
+		logger.String("stack_trace", string(stack)),
 	}
 }
 
+// bodyFields renders a captured body, redacted, or a placeholder when it
+// cannot be logged as text
+func bodyFields(name, contentType, contentEncoding string, body *cappedBuffer) []logger.Field {
+	if body.total == 0 {
+		return nil
+	}
+
+	var value string
+	switch {
+	case contentEncoding != "":
+		value = fmt.Sprintf("[%s encoded, %d bytes]", contentEncoding, body.total)
+	case !isTextualContentType(contentType):
+		value = fmt.Sprintf("[binary, %d bytes]", body.total)
+	default:
+		value = logger.RedactBody(contentType, body.data)
+	}
+
+	fields := []logger.Field{logger.String(name, value)}
+	if body.truncated() {
+		fields = append(fields, logger.Bool(name+"_truncated", true))
+	}
+	return fields
+}
+
 // logWithLevel logs with appropriate level based on HTTP status code
 func logWithLevel(log logger.Logger, status int, message string, fields ...logger.Field) {
 	switch {
@@ -193,10 +244,3 @@ func logWithLevel(log logger.Logger, status int, message string, fields ...logge
 		log.Info(message, fields...)
 	}
 }
-
-func isResponseWritten(w http.ResponseWriter) bool {
-	if rw, ok := w.(interface{ Status() int }); ok {
-		return rw.Status() != 0
-	}
-	return false
-}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"go-clean-template/internal/infrastructure/audit"
 	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
 	"go-clean-template/internal/shared/response"
 )
 
@@ -19,6 +22,8 @@ type RateLimiter struct {
 	maxTokens int
 	window    time.Duration
 	mu        sync.RWMutex
+	// lastBlockAudit limits audit records to one per client and window
+	lastBlockAudit time.Time
 }
 
 func NewRateLimiter(maxRequests int, window time.Duration) *RateLimiter {
@@ -64,6 +69,19 @@ func (rl *RateLimiter) Allow() (bool, int, time.Time) {
 	return false, 0, resetTime
 }
 
+// shouldAuditBlock reports whether a block should be audited, at most once per window
+func (rl *RateLimiter) shouldAuditBlock() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(rl.lastBlockAudit) < rl.window {
+		return false
+	}
+	rl.lastBlockAudit = now
+	return true
+}
+
 // ClientLimiterStore manages rate limiters for different clients
 type ClientLimiterStore struct {
 	limiters    map[string]*RateLimiter
@@ -100,8 +118,8 @@ func (cls *ClientLimiterStore) GetLimiter(clientID string) *RateLimiter {
 	cls.mu.Lock()
 	defer cls.mu.Unlock()
 
-	if _, exists := cls.limiters[clientID]; exists {
-		return limiter
+	if existing, exists := cls.limiters[clientID]; exists {
+		return existing
 	}
 
 	limiter = NewRateLimiter(cls.maxRequests, cls.window)
@@ -109,6 +127,73 @@ func (cls *ClientLimiterStore) GetLimiter(clientID string) *RateLimiter {
 	return limiter
 }
 
+// LimiterState describes a client's current window
+type LimiterState struct {
+	Client    string    `json:"client"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// Snapshot lists clients with requests in the current window, busiest first
+func (cls *ClientLimiterStore) Snapshot() []LimiterState {
+	now := time.Now()
+	cls.mu.RLock()
+	defer cls.mu.RUnlock()
+
+	states := make([]LimiterState, 0, len(cls.limiters))
+	for clientID, limiter := range cls.limiters {
+		limiter.mu.RLock()
+		cutoff := now.Add(-limiter.window)
+		used, resetAt := 0, now
+		for _, reqTime := range limiter.requests {
+			if reqTime.After(cutoff) {
+				if used == 0 {
+					resetAt = reqTime.Add(limiter.window)
+				}
+				used++
+			}
+		}
+		limiter.mu.RUnlock()
+
+		if used > 0 {
+			states = append(states, LimiterState{
+				Client:    clientID,
+				Used:      used,
+				Remaining: max(cls.maxRequests-used, 0),
+				ResetAt:   resetAt,
+			})
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Used != states[j].Used {
+			return states[i].Used > states[j].Used
+		}
+		return states[i].Client < states[j].Client
+	})
+	return states
+}
+
+// Reset clears a client's window; it reports whether the client was tracked
+func (cls *ClientLimiterStore) Reset(clientID string) bool {
+	cls.mu.Lock()
+	defer cls.mu.Unlock()
+
+	_, exists := cls.limiters[clientID]
+	delete(cls.limiters, clientID)
+	return exists
+}
+
+// ResetAll clears every client's window and returns how many were tracked
+func (cls *ClientLimiterStore) ResetAll() int {
+	cls.mu.Lock()
+	defer cls.mu.Unlock()
+
+	count := len(cls.limiters)
+	cls.limiters = make(map[string]*RateLimiter)
+	return count
+}
+
 func (cls *ClientLimiterStore) cleanupInactiveLimiters() {
 	now := time.Now()
 	cls.mu.Lock()
@@ -129,10 +214,11 @@ func (cls *ClientLimiterStore) cleanupInactiveLimiters() {
 	cls.lastCleanup = now
 }
 
-func RateLimit(rateLimitConfig config.RateLimitConfig) func(next http.Handler) http.Handler {
-	// Create a store for client limiters
-	store := NewClientLimiterStore(rateLimitConfig.RequestsPerMinute, time.Minute)
-
+// RateLimit limits requests per client IP. The first block of a client in
+// each window is audited; later ones in the same window are not, so a
+// misbehaving client cannot fill the audit log.
+// The store is shared with the admin API, which can inspect and reset it.
+func RateLimit(rateLimitConfig config.RateLimitConfig, store *ClientLimiterStore, recorder audit.Recorder, log logger.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip rate limiting if disabled
@@ -166,7 +252,13 @@ func RateLimit(rateLimitConfig config.RateLimitConfig) func(next http.Handler) h
 				}
 				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
 
-				response.Error(w, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED",
+				if limiter.shouldAuditBlock() {
+					event := newAuditEvent(r, audit.ActionRateLimitBlock, audit.OutcomeDenied)
+					event.Details = map[string]string{"limit": strconv.Itoa(rateLimitConfig.RequestsPerMinute)}
+					recordAudit(r, recorder, log, event)
+				}
+
+				response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED",
 					fmt.Sprintf("Rate limit exceeded. Try again in %d seconds.", retryAfter))
 				return
 			}
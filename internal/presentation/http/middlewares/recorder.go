@@ -0,0 +1,145 @@
+package middlewares
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/recording"
+	"go-clean-template/internal/shared/requestctx"
+)
+
+const recordingErrorLogInterval = time.Minute
+
+// Recorder stores sampled request/response pairs, redacted and cut at
+// max_body_bytes, so a reported problem can be replayed with cmd/replay.
+// With record_errors every 5xx is kept as well; bodies are then captured for
+// all matching requests, since the status is only known at the end. Mount it
+// inside Compress so bodies are recorded uncompressed. Recordings are kept on
+// disk or in memory, so they are always masked with redactor, whether or not
+// log redaction is turned on.
+func Recorder(cfg config.RecorderConfig, store recording.Store, redactor *logger.Redactor, log logger.Logger) func(next http.Handler) http.Handler {
+	compile := func(globs []string) []*regexp.Regexp {
+		compiled := make([]*regexp.Regexp, 0, len(globs))
+		for _, glob := range globs {
+			re, err := compilePathGlob(glob)
+			if err != nil {
+				log.Fatal("Invalid recorder configuration", logger.Error(err))
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled
+	}
+	paths, skipPaths := compile(cfg.Paths), compile(cfg.SkipPaths)
+
+	matches := func(path string) bool {
+		for _, re := range skipPaths {
+			if re.MatchString(path) {
+				return false
+			}
+		}
+		if len(paths) == 0 {
+			return true
+		}
+		for _, re := range paths {
+			if re.MatchString(path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !matches(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			sampled := cfg.SampleRate >= 1 || rand.Float64() < cfg.SampleRate
+			if !sampled && !cfg.RecordErrors {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			requestBody := &cappedBuffer{limit: cfg.MaxBodyBytes}
+			responseBody := &cappedBuffer{limit: cfg.MaxBodyBytes}
+			req := r
+			if r.Body != nil && r.Body != http.NoBody {
+				req = r.Clone(r.Context())
+				req.Body = &capturingBody{ReadCloser: r.Body, capture: requestBody}
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(responseBody)
+
+			next.ServeHTTP(ww, req)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if !sampled && status < http.StatusInternalServerError {
+				return
+			}
+
+			id := middleware.GetReqID(r.Context())
+			if id == "" {
+				id = requestctx.NewCorrelationID()
+			}
+			uri := r.URL.EscapedPath()
+			if query := redactor.Query(r.URL.RawQuery); query != "" {
+				uri += "?" + query
+			}
+
+			exchange := &recording.Exchange{
+				ID:            id,
+				Time:          start,
+				Duration:      time.Since(start),
+				CorrelationID: requestctx.CorrelationID(r.Context()),
+				Request: recording.Request{
+					Method:  r.Method,
+					URI:     uri,
+					Host:    r.Host,
+					Message: recordedMessage(redactor, r.Header, requestBody),
+				},
+				Response: recording.Response{
+					Status:  status,
+					Message: recordedMessage(redactor, ww.Header(), responseBody),
+				},
+			}
+			if err := store.Save(exchange); err != nil {
+				log.OncePer("recorder_save", recordingErrorLogInterval).WithContext(r.Context()).
+					Error("Failed to save recording", logger.Error(err))
+			}
+		})
+	}
+}
+
+// recordedMessage redacts headers and a captured body; bodies that cannot be
+// redacted as text are left out
+func recordedMessage(redactor *logger.Redactor, header http.Header, body *cappedBuffer) recording.Message {
+	message := recording.Message{
+		Headers:       redactor.Headers(header),
+		BodyBytes:     body.total,
+		BodyTruncated: body.truncated(),
+	}
+	if body.total == 0 {
+		return message
+	}
+
+	contentType := header.Get("Content-Type")
+	switch {
+	case header.Get("Content-Encoding") != "":
+		message.BodyOmitted = "encoded"
+	case !isTextualContentType(contentType):
+		message.BodyOmitted = "binary"
+	default:
+		message.Body = redactor.Body(contentType, body.data)
+	}
+	return message
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/recording"
+)
+
+func TestRecorderRedaction(t *testing.T) {
+	// Log redaction is off, which must not leak into recordings
+	log, err := logger.NewWithConfig(config.LoggingConfig{
+		Level:     "info",
+		Format:    "json",
+		Redaction: config.RedactionConfig{Enabled: false},
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig: %v", err)
+	}
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	store := recording.NewMemoryStore(10)
+	recorder := Recorder(config.RecorderConfig{
+		Enabled:      true,
+		SampleRate:   1,
+		MaxBodyBytes: 1 << 16,
+	}, store, redactor, log)
+
+	handler := middleware.RequestID(recorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		_, _ = io.WriteString(w, `{"token":"t-123","id":7}`)
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/login?access_token=k-123&page=2",
+		strings.NewReader(`{"username":"ann","password":"p-123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer b-123")
+	req.Header.Set("Cookie", "session=abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	summaries, err := store.List(10)
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("expected one recording, got %d (%v)", len(summaries), err)
+	}
+	exchange, err := store.Get(summaries[0].ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "query", value: exchange.Request.URI},
+		{name: "authorization header", value: exchange.Request.Headers["Authorization"]},
+		{name: "cookie header", value: exchange.Request.Headers["Cookie"]},
+		{name: "request body", value: exchange.Request.Body},
+		{name: "set-cookie header", value: exchange.Response.Headers["Set-Cookie"]},
+		{name: "response body", value: exchange.Response.Body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.value, logger.RedactedValue) {
+				t.Errorf("%q was not redacted", tt.value)
+			}
+			for _, secret := range []string{"k-123", "b-123", "abc", "p-123", "t-123"} {
+				if strings.Contains(tt.value, secret) {
+					t.Errorf("%q leaks %q", tt.value, secret)
+				}
+			}
+		})
+	}
+
+	if !strings.Contains(exchange.Request.URI, "page=2") || !strings.Contains(exchange.Request.Body, "ann") {
+		t.Errorf("non-sensitive values were redacted: %s %s", exchange.Request.URI, exchange.Request.Body)
+	}
+}
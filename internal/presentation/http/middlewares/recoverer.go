@@ -0,0 +1,175 @@
+package middlewares
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/sysinfo"
+	"go-clean-template/internal/shared/response"
+)
+
+// PanicKind classifies a recovered panic value
+type PanicKind string
+
+const (
+	PanicKindError PanicKind = "error" // The handler panicked with an error
+	PanicKindValue PanicKind = "value" // Any other value, usually a string
+	PanicKindAbort PanicKind = "abort" // http.ErrAbortHandler; re-panicked, not reported
+)
+
+// PanicReport describes a recovered panic for notifiers
+type PanicReport struct {
+	Kind          PanicKind
+	Value         interface{}
+	Stack         []byte
+	Method        string
+	Path          string
+	RequestID     string
+	CorrelationID string
+	// HeadersSent is true when part of the response already reached the
+	// client, so no error response could be written
+	HeadersSent bool
+}
+
+// PanicNotifier forwards recovered panics to an error tracker or pager.
+// NotifyPanic runs on the request goroutine and should not block for long.
+type PanicNotifier interface {
+	NotifyPanic(ctx context.Context, report PanicReport)
+}
+
+// PanicNotifierFunc adapts a function to PanicNotifier
+type PanicNotifierFunc func(ctx context.Context, report PanicReport)
+
+func (f PanicNotifierFunc) NotifyPanic(ctx context.Context, report PanicReport) {
+	f(ctx, report)
+}
+
+func classifyPanic(value interface{}) PanicKind {
+	err, ok := value.(error)
+	switch {
+	case !ok:
+		return PanicKindValue
+	case errors.Is(err, http.ErrAbortHandler):
+		return PanicKindAbort
+	default:
+		return PanicKindError
+	}
+}
+
+// Recoverer turns panics in later handlers into a 500 with the standard
+// error envelope, logs them with the request details and reports them to
+// notifiers. http.ErrAbortHandler is passed on so net/http can abort the
+// connection quietly. When the response was already started it cannot be
+// replaced, so the connection is aborted too rather than ending a truncated
+// body as if it were complete.
+func Recoverer(log logger.Logger, notifiers ...PanicNotifier) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoverWriter{ResponseWriter: w}
+
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+
+				kind := classifyPanic(rvr)
+				sysinfo.CountPanic(string(kind))
+				if kind == PanicKindAbort {
+					panic(rvr)
+				}
+
+				stack := debug.Stack()
+				ctx := extractRequestContext(r)
+				fields := buildPanicFields(r, ctx, rvr, stack)
+				fields = append(fields,
+					logger.String("panic_kind", string(kind)),
+					logger.Bool("headers_sent", rw.headersSent),
+				)
+				log.WithContext(r.Context()).Error("Panic recovered - Critical Error", fields...)
+
+				report := PanicReport{
+					Kind:          kind,
+					Value:         rvr,
+					Stack:         stack,
+					Method:        r.Method,
+					Path:          r.URL.Path,
+					RequestID:     ctx.requestID,
+					CorrelationID: ctx.correlationID,
+					HeadersSent:   rw.headersSent,
+				}
+				for _, notifier := range notifiers {
+					notifyPanic(r.Context(), notifier, report, log)
+				}
+
+				switch {
+				case rw.hijacked:
+					// The handler owns the connection now
+				case rw.headersSent:
+					panic(http.ErrAbortHandler)
+				default:
+					response.Error(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred")
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// notifyPanic shields the request from a notifier that panics itself
+func notifyPanic(ctx context.Context, notifier PanicNotifier, report PanicReport, log logger.Logger) {
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			log.Error("Panic notifier failed", logger.String("error", fmt.Sprint(rvr)))
+		}
+	}()
+	notifier.NotifyPanic(ctx, report)
+}
+
+// recoverWriter records whether anything reached the underlying writer, so
+// the recoverer knows if it can still send an error response regardless of
+// how later middleware wraps the writer
+type recoverWriter struct {
+	http.ResponseWriter
+	headersSent bool
+	hijacked    bool
+}
+
+func (rw *recoverWriter) WriteHeader(code int) {
+	// 1xx responses are informational and do not commit the final status
+	if code >= http.StatusOK {
+		rw.headersSent = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoverWriter) Write(p []byte) (int, error) {
+	rw.headersSent = true
+	return rw.ResponseWriter.Write(p)
+}
+
+func (rw *recoverWriter) Flush() {
+	rw.headersSent = true
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rw *recoverWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		rw.hijacked = true
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+func (rw *recoverWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/sysinfo"
+)
+
+func TestRecoverer(t *testing.T) {
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		wantRepanic     error
+		wantStatus      int
+		wantKind        PanicKind
+		wantHeadersSent bool
+		wantNotified    bool
+	}{
+		{
+			name:         "error before the response",
+			handler:      func(w http.ResponseWriter, r *http.Request) { panic(errors.New("boom")) },
+			wantStatus:   http.StatusInternalServerError,
+			wantKind:     PanicKindError,
+			wantNotified: true,
+		},
+		{
+			name:         "value before the response",
+			handler:      func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus:   http.StatusInternalServerError,
+			wantKind:     PanicKindValue,
+			wantNotified: true,
+		},
+		{
+			name: "after the headers were sent",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = io.WriteString(w, `{"partial":`)
+				panic("boom")
+			},
+			wantRepanic:     http.ErrAbortHandler,
+			wantKind:        PanicKindValue,
+			wantHeadersSent: true,
+			wantNotified:    true,
+		},
+		{
+			name: "after a flush",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.(http.Flusher).Flush()
+				panic(errors.New("boom"))
+			},
+			wantRepanic:     http.ErrAbortHandler,
+			wantKind:        PanicKindError,
+			wantHeadersSent: true,
+			wantNotified:    true,
+		},
+		{
+			name:        "abort handler",
+			handler:     func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) },
+			wantRepanic: http.ErrAbortHandler,
+			wantKind:    PanicKindAbort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []PanicReport
+			notifier := PanicNotifierFunc(func(_ context.Context, report PanicReport) {
+				reports = append(reports, report)
+			})
+			handler := RequestID()(Recoverer(logger.NewFromZap(zap.NewNop()), notifier)(tt.handler))
+			before := sysinfo.RecoveredPanics()
+
+			rec := httptest.NewRecorder()
+			repanic := func() (rvr interface{}) {
+				defer func() { rvr = recover() }()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil))
+				return nil
+			}()
+
+			if tt.wantRepanic != nil {
+				if err, ok := repanic.(error); !ok || !errors.Is(err, tt.wantRepanic) {
+					t.Fatalf("re-panicked with %v, want %v", repanic, tt.wantRepanic)
+				}
+			} else {
+				if repanic != nil {
+					t.Fatalf("unexpected panic: %v", repanic)
+				}
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("status %d, want %d", rec.Code, tt.wantStatus)
+				}
+				if body := rec.Body.String(); !strings.Contains(body, "INTERNAL_ERROR") || !strings.Contains(body, `"request_id"`) {
+					t.Errorf("body %s lacks the error envelope or request ID", body)
+				}
+			}
+
+			if got := len(reports) == 1; got != tt.wantNotified {
+				t.Fatalf("notified %d times, want notified %t", len(reports), tt.wantNotified)
+			}
+			if tt.wantNotified {
+				if reports[0].Kind != tt.wantKind || reports[0].HeadersSent != tt.wantHeadersSent || reports[0].RequestID == "" {
+					t.Errorf("unexpected report %+v", reports[0])
+				}
+			}
+
+			after := sysinfo.RecoveredPanics()
+			counted := map[PanicKind]uint64{
+				PanicKindError: after.Error - before.Error,
+				PanicKindValue: after.Value - before.Value,
+				PanicKindAbort: after.Abort - before.Abort,
+			}
+			for kind, count := range counted {
+				if want := map[bool]uint64{true: 1}[kind == tt.wantKind]; count != want {
+					t.Errorf("counted %d %s panics, want %d", count, kind, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// RequestID assigns request IDs with chi's RequestID middleware and also
+// stores them with requestctx, where shared packages such as response read
+// them without depending on the router
+func RequestID() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := requestctx.WithRequestID(r.Context(), middleware.GetReqID(r.Context()))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}))
+	}
+}
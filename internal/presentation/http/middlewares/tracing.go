@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-clean-template/internal/infrastructure/tracing"
+	"go-clean-template/internal/shared/requestctx"
+)
+
+// Tracing continues the W3C trace context sent by the caller, or starts a
+// new trace, and records a server span per request. The span is renamed
+// after the chi route pattern once routing has completed so spans group by
+// endpoint instead of by concrete URL.
+func Tracing() func(next http.Handler) http.Handler {
+	tracer := tracing.Tracer()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.URLScheme(requestScheme(r)),
+					semconv.ClientAddress(extractClientIP(r)),
+					semconv.UserAgentOriginal(r.UserAgent()),
+					semconv.NetworkProtocolVersion(fmt.Sprintf("%d.%d", r.ProtoMajor, r.ProtoMinor)),
+				),
+			)
+			defer span.End()
+
+			if requestID := middleware.GetReqID(r.Context()); requestID != "" {
+				span.SetAttributes(attribute.String("request_id", requestID))
+			}
+			if correlationID := requestctx.CorrelationID(r.Context()); correlationID != "" {
+				span.SetAttributes(attribute.String("correlation_id", correlationID))
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			r = r.WithContext(ctx)
+
+			defer func() {
+				if rvr := recover(); rvr != nil {
+					span.SetAttributes(semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+					span.SetAttributes(attribute.String("error.type", "panic"))
+					span.RecordError(fmt.Errorf("panic: %v", rvr), trace.WithStackTrace(true))
+					span.SetStatus(codes.Error, "panic")
+					nameSpan(span, r)
+					panic(rvr)
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			// Client errors are not span errors for server spans per the HTTP semantic conventions
+			if status >= http.StatusInternalServerError {
+				span.SetAttributes(attribute.String("error.type", fmt.Sprint(status)))
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			nameSpan(span, r)
+		})
+	}
+}
+
+// nameSpan renames the span after the matched route pattern
+func nameSpan(span trace.Span, r *http.Request) {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		span.SetName(r.Method + " " + pattern)
+		span.SetAttributes(semconv.HTTPRoute(pattern))
+	}
+}
+
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -1,54 +1,212 @@
 package http
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"go-clean-template/internal/infrastructure/accesslog"
+	"go-clean-template/internal/infrastructure/audit"
+	"go-clean-template/internal/infrastructure/cache"
 	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/health"
 	"go-clean-template/internal/infrastructure/logger"
+	"go-clean-template/internal/infrastructure/recording"
 	"go-clean-template/internal/presentation/http/handlers"
 	"go-clean-template/internal/presentation/http/middlewares"
 	"go-clean-template/internal/presentation/swagger"
 )
 
-func SetupRoutes(cfg *config.Config, log logger.Logger) *chi.Mux {
+// requestTimeout bounds non-streaming requests
+const requestTimeout = 60 * time.Second
+
+// cacheKeyPrefix namespaces keys when stores share a Redis instance
+const cacheKeyPrefix = "go-clean-template:"
+
+// swaggerCachePolicy lets clients reuse the static documentation assets
+var swaggerCachePolicy = middlewares.CachePolicy{Public: true, MaxAge: time.Hour}
+
+// RouteDeps are created once and shared by the public and admin routers
+type RouteDeps struct {
+	Audit      audit.Recorder
+	RateLimits *middlewares.ClientLimiterStore
+	// Recordings is nil when the recorder is disabled
+	Recordings recording.Store
+	Probes     *health.Probes
+}
+
+// NewRouteDeps opens the stores both routers need
+func NewRouteDeps(cfg *config.Config, log logger.Logger) *RouteDeps {
+	deps := &RouteDeps{
+		Audit:      newAuditRecorder(cfg, log),
+		RateLimits: middlewares.NewClientLimiterStore(cfg.RateLimit.RequestsPerMinute, time.Minute),
+		Probes:     health.New(cfg.Health),
+	}
+
+	if cfg.Recorder.Enabled {
+		store, err := recording.New(cfg.Recorder)
+		if err != nil {
+			log.Fatal("Failed to create recording store", logger.Error(err))
+		}
+		deps.Recordings = store
+	}
+	return deps
+}
+
+func SetupRoutes(cfg *config.Config, log logger.Logger, deps *RouteDeps) *chi.Mux {
 	r := chi.NewRouter()
 
-	r.Use(middleware.RequestID)
+	// Named loggers can be tuned per module through logging.modules
+	middlewareLog := log.Named("middlewares")
+	handlerLog := log.Named("handlers")
+
+	r.Use(middlewares.RequestID())
 	r.Use(middleware.RealIP)
-	r.Use(middlewares.Recoverer(log))
-	r.Use(middlewares.RequestLogger(log))
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Use(middlewares.CorrelationID())
+	r.Use(middlewares.Tracing())
+	r.Use(middlewares.ContextLogger(log))
+	if cfg.AccessLog.Enabled {
+		access, err := accesslog.New(cfg.AccessLog)
+		if err != nil {
+			log.Fatal("Failed to open access log", logger.Error(err))
+		}
+		r.Use(middlewares.AccessLog(access, middlewareLog))
+	}
+	r.Use(middlewares.Recoverer(middlewareLog))
+	r.Use(middlewares.RequestLogger(cfg.Logging.HTTP, middlewareLog))
+
+	if cfg.Compression.Enabled {
+		r.Use(middlewares.Compress(cfg.Compression))
+	}
 
 	r.Use(middlewares.CORS(cfg.CORS))
-	r.Use(middlewares.RateLimit(cfg.RateLimit))
+	r.Use(middlewares.RateLimit(cfg.RateLimit, deps.RateLimits, deps.Audit, middlewareLog))
+
+	if cfg.Idempotency.Enabled {
+		idempotencyStore, err := cache.New(cfg.Idempotency.Store, cacheKeyPrefix, cfg.Redis)
+		if err != nil {
+			log.Fatal("Failed to create idempotency store", logger.Error(err))
+		}
+		addStoreCheck(deps.Probes, "idempotency_store", idempotencyStore)
+		r.Use(middlewares.Idempotency(cfg.Idempotency, idempotencyStore, middlewareLog))
+	}
+
+	if cfg.HTTPCache.ETag {
+		r.Use(middlewares.ETag())
+	}
+
+	if deps.Recordings != nil {
+		r.Use(middlewares.Recorder(cfg.Recorder, deps.Recordings, newRecordingRedactor(cfg, log), middlewareLog))
+	}
 
-	healthHandler := handlers.NewHealthHandler(log)
+	healthHandler := handlers.NewHealthHandler(deps.Probes, handlerLog)
 
 	// API Routes
 	r.Route("/api/v1", func(r chi.Router) {
-		// Health and monitoring endpoints
-		r.Get("/health", healthHandler.Health)
-		r.Get("/heartbeat", healthHandler.Heartbeat)
-		r.Get("/system", healthHandler.SystemInfo)
-		r.Get("/ready", healthHandler.Readiness)
-		r.Get("/live", healthHandler.Liveness)
+		// Request/response endpoints are bounded by the request timeout.
+		// Streaming endpoints (NDJSON, SSE) must be registered outside this
+		// group: middleware.Timeout cancels the stream and then tries to write
+		// a 504 after the headers were sent. The response stream helpers bound
+		// each write instead of the whole response.
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(requestTimeout))
+
+			// Health and monitoring endpoints
+			r.Use(middlewares.CacheControl(middlewares.NoStorePolicy))
+			r.Get("/health", healthHandler.Health)
+			r.Get("/heartbeat", healthHandler.Heartbeat)
+			r.Get("/startup", healthHandler.Startup)
+			r.Get("/ready", healthHandler.Readiness)
+			r.Get("/live", healthHandler.Liveness)
+		})
 	})
 
 	// Legacy health endpoint for backward compatibility
-	r.Get("/health", healthHandler.Health)
+	r.With(
+		middleware.Timeout(requestTimeout),
+		middlewares.CacheControl(middlewares.NoStorePolicy),
+	).Get("/health", healthHandler.Health)
 
 	if cfg.Swagger.Enabled {
 		log.Info("Setting up Swagger documentation",
 			logger.String("route", cfg.Swagger.Route),
 			logger.String("title", cfg.Swagger.Title),
 		)
-		swagger.SetupSwagger(r, &cfg.Swagger)
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.CacheControl(swaggerCachePolicy))
+			if cfg.HTTPCache.ResponseCache.Enabled {
+				r.Use(newResponseCache(cfg, deps.Probes, middlewareLog))
+			}
+			swagger.SetupSwagger(r, &cfg.Swagger)
+		})
 	} else {
 		log.Info("Swagger documentation disabled")
 	}
 
 	return r
 }
+
+// newRecordingRedactor uses the keys and patterns of logging.redaction even
+// when log redaction is disabled, since recordings outlive the request
+func newRecordingRedactor(cfg *config.Config, log logger.Logger) *logger.Redactor {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		Keys:           cfg.Logging.Redaction.Keys,
+		Patterns:       cfg.Logging.Redaction.Patterns,
+		CustomPatterns: cfg.Logging.Redaction.CustomPatterns,
+	})
+	if err != nil {
+		log.Fatal("Invalid redaction rules for recordings", logger.Error(err))
+	}
+	return redactor
+}
+
+// newAuditRecorder opens the audit log, or discards events when auditing is off
+func newAuditRecorder(cfg *config.Config, log logger.Logger) audit.Recorder {
+	if !cfg.Audit.Enabled {
+		return audit.Nop{}
+	}
+
+	recorder, err := audit.NewFileRecorder(cfg.Audit.File)
+	if err != nil {
+		log.Fatal("Failed to open audit log", logger.Error(err))
+	}
+
+	opened := recorder.Opened()
+	if opened.TornBytes > 0 {
+		log.Warn("Removed incomplete trailing audit record",
+			logger.String("file", cfg.Audit.File),
+			logger.Int64("bytes", opened.TornBytes),
+		)
+	}
+	// The chain cannot show records cut from the end of the file, so the
+	// head is logged to keep a copy outside it
+	log.Info("Audit log opened",
+		logger.String("file", cfg.Audit.File),
+		logger.Uint64("head_seq", opened.LastSeq),
+		logger.String("head_hash", opened.LastHash),
+	)
+	return recorder
+}
+
+// newResponseCache creates the server-side GET cache for routes that opt in
+func newResponseCache(cfg *config.Config, probes *health.Probes, log logger.Logger) func(next http.Handler) http.Handler {
+	store, err := cache.New(cfg.HTTPCache.ResponseCache.Store, cacheKeyPrefix, cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to create response cache store", logger.Error(err))
+	}
+	addStoreCheck(probes, "response_cache_store", store)
+
+	return middlewares.ResponseCache(store, middlewares.ResponseCacheOptions{
+		TTL:         time.Duration(cfg.HTTPCache.ResponseCache.TTL) * time.Second,
+		VaryHeaders: cfg.HTTPCache.ResponseCache.VaryHeaders,
+	}, log)
+}
+
+// addStoreCheck makes readiness depend on stores backed by a remote service
+func addStoreCheck(probes *health.Probes, name string, store cache.Store) {
+	if pinger, ok := store.(cache.Pinger); ok {
+		probes.AddCheck(health.Check{Name: name, Critical: true, Run: pinger.Ping})
+	}
+}
@@ -2,27 +2,43 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"go-clean-template/internal/infrastructure/config"
+	"go-clean-template/internal/infrastructure/health"
 	"go-clean-template/internal/infrastructure/logger"
 )
 
 type Server struct {
 	server *http.Server
-	config *config.Config
-	logger logger.Logger
+	// admin is nil when the admin listener is disabled
+	admin  *http.Server
+	probes *health.Probes
+	// listening completes the startup task that waits for the listener
+	listening     func(error)
+	stopHeartbeat context.CancelFunc
+	config        *config.Config
+	logger        logger.Logger
 }
 
 func NewServer(config *config.Config, log logger.Logger) *Server {
+	deps := NewRouteDeps(config, log)
+
 	// Setup routes with configuration and logger
-	router := SetupRoutes(config, log)
+	router := SetupRoutes(config, log, deps)
 
+	// Streaming responses extend the write deadline on every write through
+	// http.ResponseController, so WriteTimeout only bounds buffered responses
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", config.Server.Port),
 		Handler:      router,
@@ -31,13 +47,78 @@ func NewServer(config *config.Config, log logger.Logger) *Server {
 	}
 
 	return &Server{
-		server: server,
-		config: config,
-		logger: log,
+		server:    server,
+		admin:     newAdminServer(config, log, deps, router),
+		probes:    deps.Probes,
+		listening: deps.Probes.StartupTask("listener"),
+		config:    config,
+		logger:    log,
+	}
+}
+
+// Probes lets initialization code register startup tasks, readiness checks
+// and heartbeats before Start
+func (s *Server) Probes() *health.Probes {
+	return s.probes
+}
+
+// newAdminServer creates the admin listener. It is left out when no caller
+// could authenticate against it.
+func newAdminServer(cfg *config.Config, log logger.Logger, deps *RouteDeps, public chi.Routes) *http.Server {
+	if !cfg.Admin.Enabled {
+		return nil
+	}
+	if cfg.Admin.Token == "" && cfg.Admin.TLS.ClientCAFile == "" {
+		log.Warn("Admin API disabled: set ADMIN_TOKEN or admin.tls.client_ca_file")
+		return nil
+	}
+
+	// WriteTimeout stays unset so pprof can stream long profiles and traces
+	server := &http.Server{
+		Addr:              net.JoinHostPort(cfg.Admin.Host, cfg.Admin.Port),
+		Handler:           SetupAdminRoutes(cfg, log, deps, public),
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadTimeout) * time.Second,
+	}
+
+	if cfg.Admin.TLS.ClientCAFile != "" {
+		if cfg.Admin.TLS.CertFile == "" {
+			log.Fatal("admin.tls.client_ca_file requires admin.tls.cert_file and key_file")
+		}
+		pem, err := os.ReadFile(cfg.Admin.TLS.ClientCAFile)
+		if err != nil {
+			log.Fatal("Failed to read admin client CA", logger.Error(err))
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			log.Fatal("Admin client CA contains no certificates", logger.String("file", cfg.Admin.TLS.ClientCAFile))
+		}
+		// Token callers may still connect without a certificate
+		clientAuth := tls.RequireAndVerifyClientCert
+		if cfg.Admin.Token != "" {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  pool,
+			ClientAuth: clientAuth,
+		}
 	}
+	return server
 }
 
 func (s *Server) Start() error {
+	// Listening before serving lets startup pass only once the port is bound
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		s.listening(err)
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+	s.listening(nil)
+
+	ctx, stopHeartbeat := context.WithCancel(context.Background())
+	s.stopHeartbeat = stopHeartbeat
+	go s.probes.Run(ctx)
+
 	go func() {
 		s.logger.Info("HTTP server starting",
 			logger.String("port", s.config.Server.Port),
@@ -53,11 +134,15 @@ func (s *Server) Start() error {
 			)
 		}
 
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			s.logger.Fatal("HTTP server failed to start", logger.Error(err))
 		}
 	}()
 
+	if s.admin != nil {
+		go s.serveAdmin()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -65,12 +150,51 @@ func (s *Server) Start() error {
 	return s.Shutdown()
 }
 
+func (s *Server) serveAdmin() {
+	tlsConfig := s.config.Admin.TLS
+	s.logger.Info("Admin server starting",
+		logger.String("addr", s.admin.Addr),
+		logger.Bool("tls", tlsConfig.CertFile != ""),
+		logger.Bool("mtls", tlsConfig.ClientCAFile != ""),
+		logger.Bool("profiling", s.config.Profiling.Endpoints),
+	)
+
+	var err error
+	if tlsConfig.CertFile != "" {
+		err = s.admin.ListenAndServeTLS(tlsConfig.CertFile, tlsConfig.KeyFile)
+	} else {
+		err = s.admin.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		s.logger.Fatal("Admin server failed to start", logger.Error(err))
+	}
+}
+
 func (s *Server) Shutdown() error {
+	// Readiness fails first so load balancers stop routing new requests
+	// here while the listeners still serve them
+	s.probes.Drain()
+	if delay := time.Duration(s.config.Health.DrainDelay) * time.Second; delay > 0 {
+		s.logger.Info("Draining before shutdown", logger.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+	// The heartbeat keeps liveness passing until both listeners have
+	// finished draining in-flight requests
+	if s.stopHeartbeat != nil {
+		defer s.stopHeartbeat()
+	}
+
 	s.logger.Info("Initiating graceful server shutdown")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if s.admin != nil {
+		if err := s.admin.Shutdown(ctx); err != nil {
+			s.logger.Error("Admin server forced to shutdown", logger.Error(err))
+		}
+	}
+
 	if err := s.server.Shutdown(ctx); err != nil {
 		s.logger.Error("Server forced to shutdown", logger.Error(err))
 		return fmt.Errorf("server forced to shutdown: %w", err)
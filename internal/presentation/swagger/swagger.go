@@ -21,7 +21,7 @@ func Initialize(cfg SwaggerConfig) {
 	docs.SwaggerInfo.Schemes = cfg.Schemes
 }
 
-func SetupSwagger(r *chi.Mux, swaggerConfig *SwaggerConfig) {
+func SetupSwagger(r chi.Router, swaggerConfig *SwaggerConfig) {
 	if !swaggerConfig.Enabled {
 		return
 	}
@@ -76,6 +76,22 @@ func ConflictWithCause(code, message string, cause error) *AppError {
 	return NewAppErrorWithCause(code, message, http.StatusConflict, cause)
 }
 
+func PayloadTooLarge(code, message string) *AppError {
+	return NewAppError(code, message, http.StatusRequestEntityTooLarge)
+}
+
+func PayloadTooLargeWithCause(code, message string, cause error) *AppError {
+	return NewAppErrorWithCause(code, message, http.StatusRequestEntityTooLarge, cause)
+}
+
+func UnsupportedMediaType(code, message string) *AppError {
+	return NewAppError(code, message, http.StatusUnsupportedMediaType)
+}
+
+func UnsupportedMediaTypeWithCause(code, message string, cause error) *AppError {
+	return NewAppErrorWithCause(code, message, http.StatusUnsupportedMediaType, cause)
+}
+
 func InternalServer(code, message string) *AppError {
 	return NewAppError(code, message, http.StatusInternalServerError)
 }
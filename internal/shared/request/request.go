@@ -0,0 +1,183 @@
+package request
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"go-clean-template/internal/shared/errors"
+)
+
+// DefaultMaxBodyBytes caps request bodies when Options.MaxBytes is not set
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MB
+
+// Options configures Decode
+type Options struct {
+	// MaxBytes caps the body size, applied both before and after gzip decompression
+	MaxBytes int64
+	// ContentTypes lists the accepted media types; defaults to application/json
+	ContentTypes []string
+	// AllowUnknownFields disables strict mode
+	AllowUnknownFields bool
+	// AllowEmpty returns the zero value instead of an error for empty bodies
+	AllowEmpty bool
+}
+
+// Decode reads a single JSON document from the request body into T. It
+// enforces the body size limit and Content-Type, rejects unknown fields and
+// trailing data, and transparently inflates gzip encoded bodies.
+func Decode[T any](w http.ResponseWriter, r *http.Request, opts ...Options) (T, *errors.AppError) {
+	var target T
+	opt := resolveOptions(opts)
+
+	// An absent body is handled before the Content-Type check, since clients
+	// sending nothing usually send no Content-Type either
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		if opt.AllowEmpty {
+			return target, nil
+		}
+		return target, errors.BadRequest("EMPTY_BODY", "Request body must not be empty")
+	}
+
+	if appErr := checkContentType(r, opt.ContentTypes); appErr != nil {
+		return target, appErr
+	}
+
+	body, appErr := bodyReader(w, r, opt.MaxBytes)
+	if appErr != nil {
+		return target, appErr
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	decoder := json.NewDecoder(body)
+	if !opt.AllowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(&target); err != nil {
+		if stderrors.Is(err, io.EOF) && opt.AllowEmpty {
+			return target, nil
+		}
+		return target, decodeError(err, opt.MaxBytes)
+	}
+
+	// A second document, or any non-whitespace after the first one, is rejected
+	if err := decoder.Decode(&struct{}{}); !stderrors.Is(err, io.EOF) {
+		if appErr := sizeError(err, opt.MaxBytes); appErr != nil {
+			return target, appErr
+		}
+		return target, errors.BadRequestWithCause("TRAILING_DATA",
+			"Request body must contain a single JSON document", err)
+	}
+
+	return target, nil
+}
+
+func resolveOptions(opts []Options) Options {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxBytes <= 0 {
+		opt.MaxBytes = DefaultMaxBodyBytes
+	}
+	if len(opt.ContentTypes) == 0 {
+		opt.ContentTypes = []string{"application/json"}
+	}
+	return opt
+}
+
+func checkContentType(r *http.Request, allowed []string) *errors.AppError {
+	header := r.Header.Get("Content-Type")
+	if header == "" {
+		return errors.UnsupportedMediaType("UNSUPPORTED_MEDIA_TYPE",
+			fmt.Sprintf("Content-Type header is required, expected %s", strings.Join(allowed, ", ")))
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return errors.UnsupportedMediaTypeWithCause("UNSUPPORTED_MEDIA_TYPE",
+			"Content-Type header is malformed", err)
+	}
+
+	for _, candidate := range allowed {
+		if strings.EqualFold(mediaType, candidate) {
+			return nil
+		}
+	}
+
+	return errors.UnsupportedMediaType("UNSUPPORTED_MEDIA_TYPE",
+		fmt.Sprintf("Content-Type %s is not supported, expected %s", mediaType, strings.Join(allowed, ", ")))
+}
+
+// bodyReader applies the size limit and undoes the Content-Encoding
+func bodyReader(w http.ResponseWriter, r *http.Request, maxBytes int64) (io.ReadCloser, *errors.AppError) {
+	body := http.MaxBytesReader(w, r.Body, maxBytes)
+
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			if appErr := sizeError(err, maxBytes); appErr != nil {
+				return nil, appErr
+			}
+			return nil, errors.BadRequestWithCause("INVALID_GZIP", "Request body is not valid gzip data", err)
+		}
+		// Limit the inflated size as well so a small payload cannot expand unbounded
+		return http.MaxBytesReader(w, gz, maxBytes), nil
+	default:
+		return nil, errors.UnsupportedMediaType("UNSUPPORTED_CONTENT_ENCODING",
+			fmt.Sprintf("Content-Encoding %s is not supported", encoding))
+	}
+}
+
+func decodeError(err error, maxBytes int64) *errors.AppError {
+	if appErr := sizeError(err, maxBytes); appErr != nil {
+		return appErr
+	}
+
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		gzipChecksum = stderrors.Is(err, gzip.ErrChecksum) || stderrors.Is(err, gzip.ErrHeader)
+	)
+
+	switch {
+	case gzipChecksum:
+		return errors.BadRequestWithCause("INVALID_GZIP", "Request body is not valid gzip data", err)
+	case stderrors.Is(err, io.EOF):
+		return errors.BadRequestWithCause("EMPTY_BODY", "Request body must not be empty", err)
+	case stderrors.Is(err, io.ErrUnexpectedEOF):
+		return errors.BadRequestWithCause("INVALID_JSON", "Request body contains truncated JSON", err)
+	case stderrors.As(err, &syntaxErr):
+		return errors.BadRequestWithCause("INVALID_JSON",
+			fmt.Sprintf("Request body contains malformed JSON at position %d", syntaxErr.Offset), err)
+	case stderrors.As(err, &typeErr):
+		return errors.BadRequestWithCause("INVALID_FIELD_TYPE",
+			fmt.Sprintf("Field %q must be of type %s", typeErr.Field, typeErr.Type), err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return errors.BadRequestWithCause("UNKNOWN_FIELD", fmt.Sprintf("Request body contains unknown field %s", field), err)
+	default:
+		return errors.BadRequestWithCause("INVALID_JSON", "Request body could not be decoded", err)
+	}
+}
+
+func sizeError(err error, maxBytes int64) *errors.AppError {
+	var maxErr *http.MaxBytesError
+	if stderrors.As(err, &maxErr) {
+		return errors.PayloadTooLargeWithCause("BODY_TOO_LARGE",
+			fmt.Sprintf("Request body must not exceed %d bytes", maxBytes), err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type decodeTarget struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	large := `{"name":"` + strings.Repeat("a", 2048) + `"}`
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		encoding    string
+		opts        Options
+		wantStatus  int
+		wantCode    string
+		want        decodeTarget
+	}{
+		{name: "valid", body: []byte(`{"name":"a","count":2}`), contentType: "application/json", want: decodeTarget{Name: "a", Count: 2}},
+		{name: "content type parameters", body: []byte(`{"name":"a"}`), contentType: "application/json; charset=utf-8", want: decodeTarget{Name: "a"}},
+		{name: "gzip", body: gzipped(t, `{"name":"a"}`), contentType: "application/json", encoding: "gzip", want: decodeTarget{Name: "a"}},
+		{name: "empty body", contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "EMPTY_BODY"},
+		{name: "allowed empty body", opts: Options{AllowEmpty: true}},
+		{name: "missing content type", body: []byte(`{}`), wantStatus: http.StatusUnsupportedMediaType, wantCode: "UNSUPPORTED_MEDIA_TYPE"},
+		{name: "other content type", body: []byte(`{}`), contentType: "text/plain", wantStatus: http.StatusUnsupportedMediaType, wantCode: "UNSUPPORTED_MEDIA_TYPE"},
+		{name: "other content encoding", body: []byte(`{}`), contentType: "application/json", encoding: "br", wantStatus: http.StatusUnsupportedMediaType, wantCode: "UNSUPPORTED_CONTENT_ENCODING"},
+		{name: "too large", body: []byte(large), contentType: "application/json", opts: Options{MaxBytes: 1024}, wantStatus: http.StatusRequestEntityTooLarge, wantCode: "BODY_TOO_LARGE"},
+		{name: "too large once inflated", body: gzipped(t, large), contentType: "application/json", encoding: "gzip", opts: Options{MaxBytes: 1024}, wantStatus: http.StatusRequestEntityTooLarge, wantCode: "BODY_TOO_LARGE"},
+		{name: "invalid gzip", body: []byte(`{"name":"a"}`), contentType: "application/json", encoding: "gzip", wantStatus: http.StatusBadRequest, wantCode: "INVALID_GZIP"},
+		{name: "trailing document", body: []byte(`{"name":"a"}{"name":"b"}`), contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "TRAILING_DATA"},
+		{name: "trailing garbage", body: []byte(`{"name":"a"} x`), contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "TRAILING_DATA"},
+		{name: "trailing whitespace", body: []byte("{\"name\":\"a\"}\n\t "), contentType: "application/json", want: decodeTarget{Name: "a"}},
+		{name: "malformed", body: []byte(`{"name":}`), contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "INVALID_JSON"},
+		{name: "truncated", body: []byte(`{"name":"a"`), contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "INVALID_JSON"},
+		{name: "wrong field type", body: []byte(`{"count":"2"}`), contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "INVALID_FIELD_TYPE"},
+		{name: "unknown field", body: []byte(`{"other":1}`), contentType: "application/json", wantStatus: http.StatusBadRequest, wantCode: "UNKNOWN_FIELD"},
+		{name: "allowed unknown field", body: []byte(`{"other":1,"name":"a"}`), contentType: "application/json", opts: Options{AllowUnknownFields: true}, want: decodeTarget{Name: "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/items", bytes.NewReader(tt.body))
+			if tt.body == nil {
+				req.Body = http.NoBody
+				req.ContentLength = 0
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			got, appErr := Decode[decodeTarget](httptest.NewRecorder(), req, tt.opts)
+
+			if tt.wantCode == "" {
+				if appErr != nil {
+					t.Fatalf("unexpected error %s: %s", appErr.Code, appErr.Message)
+				}
+				if got != tt.want {
+					t.Errorf("decoded %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+			if appErr == nil {
+				t.Fatalf("expected %s, decoded %+v", tt.wantCode, got)
+			}
+			if appErr.Code != tt.wantCode || appErr.Status != tt.wantStatus {
+				t.Errorf("got %d %s (%s), want %d %s", appErr.Status, appErr.Code, appErr.Message, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package requestctx
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// CorrelationHeader is the header used to return and forward correlation IDs
+const CorrelationHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID stores the correlation ID for the request in ctx
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID stored in ctx, or ""
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// NewCorrelationID generates a random RFC 4122 version 4 UUID
+func NewCorrelationID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type requestIDKey struct{}
+
+// WithRequestID stores the ID assigned to the request in ctx, so packages
+// outside the router can quote it
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type userIDKey struct{}
+
+// WithUserID stores the authenticated user for the request in ctx. It is set
+// by authentication middleware and read by logging and auditing.
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserID returns the authenticated user stored in ctx, or ""
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
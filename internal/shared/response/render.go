@@ -0,0 +1,195 @@
+package response
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Renderer encodes a response payload into a specific media type
+type Renderer interface {
+	ContentType() string
+	Render(buf *bytes.Buffer, data interface{}) error
+}
+
+// Supporter is implemented by renderers that can only encode some payloads,
+// such as CSV which only handles lists
+type Supporter interface {
+	Supports(data interface{}) bool
+}
+
+type registeredRenderer struct {
+	mediaType string
+	renderer  Renderer
+}
+
+// Registry holds renderers in server preference order
+type Registry struct {
+	renderers []registeredRenderer
+	mu        sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds or replaces the renderer for a media type
+func (reg *Registry) Register(mediaType string, renderer Renderer) {
+	mediaType = strings.ToLower(mediaType)
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for i, existing := range reg.renderers {
+		if existing.mediaType == mediaType {
+			reg.renderers[i].renderer = renderer
+			return
+		}
+	}
+	reg.renderers = append(reg.renderers, registeredRenderer{mediaType: mediaType, renderer: renderer})
+}
+
+// Negotiate picks the renderer that best matches the request's Accept header
+// and is able to encode data. It returns false when nothing acceptable exists.
+func (reg *Registry) Negotiate(r *http.Request, data interface{}) (Renderer, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	if len(reg.renderers) == 0 {
+		return nil, false
+	}
+
+	var accept string
+	if r != nil {
+		accept = r.Header.Get("Accept")
+	}
+
+	var (
+		best        Renderer
+		bestQuality float64
+		bestSpec    = -1
+	)
+
+	for _, candidate := range reg.renderers {
+		if s, ok := candidate.renderer.(Supporter); ok && !s.Supports(data) {
+			continue
+		}
+
+		quality, specificity := matchAccept(accept, candidate.mediaType)
+		if quality <= 0 {
+			continue
+		}
+
+		// Higher quality wins, then the more specific media range; ties keep server order
+		if quality > bestQuality || (quality == bestQuality && specificity > bestSpec) {
+			best = candidate.renderer
+			bestQuality = quality
+			bestSpec = specificity
+		}
+	}
+
+	if best == nil {
+		return nil, false
+	}
+
+	if isPrettyRequested(r) {
+		if jr, ok := best.(JSONRenderer); ok {
+			jr.Indent = true
+			best = jr
+		}
+	}
+
+	return best, true
+}
+
+// DefaultRegistry is used by the package level response helpers
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	reg := NewRegistry()
+	reg.Register(MediaTypeJSON, JSONRenderer{})
+	reg.Register(MediaTypeMsgPack, MsgPackRenderer{})
+	reg.Register(MediaTypeXMsgPack, MsgPackRenderer{})
+	reg.Register(MediaTypeCSV, CSVRenderer{})
+	reg.Register(MediaTypeXML, XMLRenderer{})
+	reg.Register(MediaTypeTextXML, XMLRenderer{})
+	return reg
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// matchAccept returns the quality the Accept header assigns to mediaType and
+// how specific the matching range was (0 for */*, 1 for type/*, 2 for exact)
+func matchAccept(accept, mediaType string) (float64, int) {
+	if strings.TrimSpace(accept) == "" {
+		return 1, 0
+	}
+
+	quality, specificity := 0.0, -1
+	for _, ar := range parseAccept(accept) {
+		spec := rangeSpecificity(ar.mediaType, mediaType)
+		if spec > specificity {
+			quality, specificity = ar.quality, spec
+		}
+	}
+	return quality, specificity
+}
+
+func rangeSpecificity(rangeType, mediaType string) int {
+	switch {
+	case rangeType == mediaType:
+		return 2
+	case rangeType == "*/*" || rangeType == "*":
+		return 0
+	case strings.HasSuffix(rangeType, "/*"):
+		if strings.HasPrefix(mediaType, strings.TrimSuffix(rangeType, "*")) {
+			return 1
+		}
+	}
+	return -1
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		segments := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(segments[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range segments[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				quality = q
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+func isPrettyRequested(r *http.Request) bool {
+	if r == nil || r.URL == nil {
+		return false
+	}
+	values, ok := r.URL.Query()["pretty"]
+	if !ok {
+		return false
+	}
+	if len(values) == 0 || values[0] == "" {
+		return true
+	}
+	pretty, err := strconv.ParseBool(values[0])
+	return err == nil && pretty
+}
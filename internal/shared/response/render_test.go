@@ -0,0 +1,82 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type renderItem struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func TestSuccessNegotiation(t *testing.T) {
+	list := []renderItem{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+
+	tests := []struct {
+		name            string
+		accept          string
+		data            interface{}
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "no Accept", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeJSON, wantBody: `[{"id":1,"name":"a"}`},
+		{name: "wildcard", accept: "*/*", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeJSON},
+		{name: "exact XML", accept: "application/xml", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeXML, wantBody: "<response><data>"},
+		{name: "text XML", accept: "text/xml", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeXML},
+		{name: "CSV list", accept: "text/csv", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeCSV, wantBody: "id,name\n1,a\n2,b\n"},
+		{name: "MessagePack", accept: "application/msgpack", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeMsgPack},
+		{name: "higher q-value wins", accept: "application/json;q=0.5, text/csv;q=0.9", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeCSV},
+		{name: "specific range wins on a tie", accept: "text/*, text/xml", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeXML},
+		{name: "q=0 excludes", accept: "application/json;q=0, application/xml", data: list, wantStatus: http.StatusOK, wantContentType: MediaTypeXML},
+		{name: "CSV cannot encode an object", accept: "text/csv, application/json;q=0.1", data: renderItem{ID: 1}, wantStatus: http.StatusOK, wantContentType: MediaTypeJSON},
+		{name: "XML cannot encode a map", accept: "application/xml, */*;q=0.1", data: map[string]int{"a": 1}, wantStatus: http.StatusOK, wantContentType: MediaTypeJSON},
+		{name: "nothing acceptable", accept: "image/png", data: list, wantStatus: http.StatusNotAcceptable, wantContentType: MediaTypeJSON, wantBody: "NOT_ACCEPTABLE"},
+		{name: "only unsupported CSV", accept: "text/csv", data: renderItem{ID: 1}, wantStatus: http.StatusNotAcceptable, wantContentType: MediaTypeJSON, wantBody: "NOT_ACCEPTABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/items", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			Success(rec, req, tt.data)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantContentType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept" {
+				t.Errorf("Vary = %v, want Accept", got)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body %q does not contain %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestErrorFallsBackToJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/items?pretty", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+
+	Error(rec, req, http.StatusNotFound, "NOT_FOUND", "Item not found")
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want the original 404", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, MediaTypeJSON) {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+	if !strings.Contains(rec.Body.String(), "\n  ") {
+		t.Errorf("pretty was ignored: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,342 @@
+package response
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeMsgPack  = "application/msgpack"
+	MediaTypeXMsgPack = "application/x-msgpack"
+	MediaTypeCSV      = "text/csv"
+	MediaTypeXML      = "application/xml"
+	MediaTypeTextXML  = "text/xml"
+)
+
+// JSONRenderer encodes payloads as JSON, indented when Indent is set
+type JSONRenderer struct {
+	Indent bool
+}
+
+func (JSONRenderer) ContentType() string {
+	return MediaTypeJSON + "; charset=utf-8"
+}
+
+func (jr JSONRenderer) Render(buf *bytes.Buffer, data interface{}) error {
+	encoder := json.NewEncoder(buf)
+	if jr.Indent {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(data)
+}
+
+// MsgPackRenderer encodes payloads as MessagePack reusing the json struct tags
+type MsgPackRenderer struct{}
+
+func (MsgPackRenderer) ContentType() string {
+	return MediaTypeMsgPack
+}
+
+func (MsgPackRenderer) Render(buf *bytes.Buffer, data interface{}) error {
+	encoder := msgpack.NewEncoder(buf)
+	encoder.SetCustomStructTag("json")
+	encoder.SetOmitEmpty(true)
+	return encoder.Encode(data)
+}
+
+// XMLRenderer encodes payloads as XML wrapped in a <response> root element
+type XMLRenderer struct{}
+
+type xmlEnvelope struct {
+	XMLName xml.Name    `xml:"response"`
+	Data    interface{} `xml:"data"`
+}
+
+func (XMLRenderer) ContentType() string {
+	return MediaTypeXML + "; charset=utf-8"
+}
+
+// Supports rejects payloads encoding/xml cannot marshal, such as maps, so
+// negotiation falls back to another renderer instead of failing mid-write
+func (XMLRenderer) Supports(data interface{}) bool {
+	return xmlEncodable(reflect.ValueOf(data), 0)
+}
+
+func (XMLRenderer) Render(buf *bytes.Buffer, data interface{}) error {
+	buf.WriteString(xml.Header)
+	return xml.NewEncoder(buf).Encode(xmlEnvelope{Data: data})
+}
+
+// CSVRenderer encodes list payloads as CSV with a header row. It only
+// supports slices of structs or maps, and paged responses wrapping them.
+type CSVRenderer struct{}
+
+func (CSVRenderer) ContentType() string {
+	return MediaTypeCSV + "; charset=utf-8"
+}
+
+func (CSVRenderer) Supports(data interface{}) bool {
+	_, ok := csvRows(data)
+	return ok
+}
+
+func (CSVRenderer) Render(buf *bytes.Buffer, data interface{}) error {
+	rows, ok := csvRows(data)
+	if !ok {
+		return fmt.Errorf("csv renderer: unsupported payload type %T", data)
+	}
+
+	header, records, err := csvRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(buf)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// csvRows unwraps paged responses and returns the list to encode
+func csvRows(data interface{}) (reflect.Value, bool) {
+	if paged, ok := data.(pagedResponse); ok {
+		data = paged.Data
+	}
+
+	rv := reflect.ValueOf(data)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return reflect.Value{}, false
+	}
+
+	switch indirectType(rv.Type().Elem()).Kind() {
+	case reflect.Struct, reflect.Map:
+		return rv, true
+	case reflect.Interface:
+		// Interface slices are only accepted when every element is a row
+		for i := 0; i < rv.Len(); i++ {
+			if !isCSVRow(rv.Index(i)) {
+				return reflect.Value{}, false
+			}
+		}
+		return rv, true
+	default:
+		return reflect.Value{}, false
+	}
+}
+
+func isCSVRow(item reflect.Value) bool {
+	for item.Kind() == reflect.Interface || item.Kind() == reflect.Pointer {
+		if item.IsNil() {
+			return true
+		}
+		item = item.Elem()
+	}
+	return item.Kind() == reflect.Struct || item.Kind() == reflect.Map
+}
+
+func csvRecords(rows reflect.Value) ([]string, [][]string, error) {
+	// Flatten every row into column/value pairs first so maps with
+	// different key sets still share a single header
+	flattened := make([]map[string]string, 0, rows.Len())
+	var header []string
+	seen := make(map[string]bool)
+
+	for i := 0; i < rows.Len(); i++ {
+		columns, values, err := csvColumns(rows.Index(i))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		row := make(map[string]string, len(columns))
+		for j, column := range columns {
+			row[column] = values[j]
+			if !seen[column] {
+				seen[column] = true
+				header = append(header, column)
+			}
+		}
+		flattened = append(flattened, row)
+	}
+
+	records := make([][]string, 0, len(flattened))
+	for _, row := range flattened {
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = row[column]
+		}
+		records = append(records, record)
+	}
+
+	return header, records, nil
+}
+
+func csvColumns(item reflect.Value) ([]string, []string, error) {
+	for item.Kind() == reflect.Interface || item.Kind() == reflect.Pointer {
+		if item.IsNil() {
+			return nil, nil, nil
+		}
+		item = item.Elem()
+	}
+
+	switch item.Kind() {
+	case reflect.Struct:
+		return structColumns(item)
+	case reflect.Map:
+		return mapColumns(item)
+	default:
+		return nil, nil, fmt.Errorf("csv renderer: unsupported row type %s", item.Type())
+	}
+}
+
+func structColumns(item reflect.Value) ([]string, []string, error) {
+	var columns, values []string
+	itemType := item.Type()
+
+	for i := 0; i < itemType.NumField(); i++ {
+		field := itemType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		value, err := csvValue(item.Field(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = append(columns, name)
+		values = append(values, value)
+	}
+
+	return columns, values, nil
+}
+
+func mapColumns(item reflect.Value) ([]string, []string, error) {
+	keys := make([]string, 0, item.Len())
+	byKey := make(map[string]reflect.Value, item.Len())
+	for _, key := range item.MapKeys() {
+		name := fmt.Sprint(key.Interface())
+		keys = append(keys, name)
+		byKey[name] = item.MapIndex(key)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := csvValue(byKey[key])
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, value)
+	}
+
+	return keys, values, nil
+}
+
+// csvValue renders scalars directly and nested values as compact JSON
+func csvValue(value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		encoded, err := json.Marshal(value.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	default:
+		return fmt.Sprint(value.Interface()), nil
+	}
+}
+
+// maxXMLDepth bounds the payload walk so self-referencing values terminate
+const maxXMLDepth = 32
+
+var xmlMarshalerType = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+
+// xmlEncodable walks the payload and reports whether encoding/xml can
+// marshal it, which rules out maps, channels and functions at any depth
+func xmlEncodable(value reflect.Value, depth int) bool {
+	if depth > maxXMLDepth {
+		return false
+	}
+	if !value.IsValid() {
+		return true
+	}
+	if value.Type().Implements(xmlMarshalerType) {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Interface, reflect.Pointer:
+		if value.IsNil() {
+			return true
+		}
+		return xmlEncodable(value.Elem(), depth+1)
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if !field.IsExported() || field.Tag.Get("xml") == "-" {
+				continue
+			}
+			if !xmlEncodable(value.Field(i), depth+1) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		// Byte slices are written as character data
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+		for i := 0; i < value.Len(); i++ {
+			if !xmlEncodable(value.Index(i), depth+1) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
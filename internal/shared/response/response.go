@@ -1,50 +1,61 @@
 package response
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"go-clean-template/internal/shared/errors"
+	"go-clean-template/internal/shared/requestctx"
 )
 
 type SuccessResponse struct {
-	Meta *Meta `json:"meta,omitempty"`
+	Meta *Meta `json:"meta,omitempty" xml:"meta,omitempty"`
 }
 
 type ErrorResponse struct {
-	Error *ErrorInfo `json:"error"`
+	Error *ErrorInfo `json:"error" xml:"error"`
 }
 
 type ErrorInfo struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code      string `json:"code" xml:"code"`
+	Message   string `json:"message" xml:"message"`
+	RequestID string `json:"request_id,omitempty" xml:"request_id,omitempty"`
 }
 
 type Meta struct {
-	Page       int `json:"page,omitempty"`
-	Limit      int `json:"limit,omitempty"`
-	Total      int `json:"total,omitempty"`
-	TotalPages int `json:"total_pages,omitempty"`
+	Page       int `json:"page,omitempty" xml:"page,omitempty"`
+	Limit      int `json:"limit,omitempty" xml:"limit,omitempty"`
+	Total      int `json:"total,omitempty" xml:"total,omitempty"`
+	TotalPages int `json:"total_pages,omitempty" xml:"total_pages,omitempty"`
 }
 
-func Success(w http.ResponseWriter, data interface{}) {
-	sendJSON(w, http.StatusOK, data)
+// pagedResponse is the list envelope written by SuccessWithMeta
+type pagedResponse struct {
+	Data interface{} `json:"data" xml:"item"`
+	Meta *Meta       `json:"meta" xml:"meta"`
 }
 
-func SuccessWithMeta(w http.ResponseWriter, data interface{}, meta *Meta) {
-	response := struct {
-		Data interface{} `json:"data"`
-		Meta *Meta       `json:"meta"`
-	}{
+func Success(w http.ResponseWriter, r *http.Request, data interface{}) {
+	render(w, r, http.StatusOK, data)
+}
+
+// WithStatus renders data with a status other than 200, such as a failing
+// health probe
+func WithStatus(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	render(w, r, statusCode, data)
+}
+
+func SuccessWithMeta(w http.ResponseWriter, r *http.Request, data interface{}, meta *Meta) {
+	render(w, r, http.StatusOK, pagedResponse{
 		Data: data,
 		Meta: meta,
-	}
-	sendJSON(w, http.StatusOK, response)
+	})
 }
 
-func Error(w http.ResponseWriter, status int, code, message string) {
-	sendJSON(w, status, ErrorResponse{
+func Error(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	renderError(w, r, status, ErrorResponse{
 		Error: &ErrorInfo{
 			Code:    code,
 			Message: message,
@@ -52,8 +63,8 @@ func Error(w http.ResponseWriter, status int, code, message string) {
 	})
 }
 
-func ErrorFromAppError(w http.ResponseWriter, err *errors.AppError) {
-	sendJSON(w, err.Status, ErrorResponse{
+func ErrorFromAppError(w http.ResponseWriter, r *http.Request, err *errors.AppError) {
+	renderError(w, r, err.Status, ErrorResponse{
 		Error: &ErrorInfo{
 			Code:    err.Code,
 			Message: err.Message,
@@ -84,8 +95,59 @@ func GetFullErrorChain(err *errors.AppError) []string {
 	return chain
 }
 
-func sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+// render negotiates a renderer from the Accept header and writes the payload,
+// answering 406 when no registered renderer is acceptable
+func render(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	w.Header().Add("Vary", "Accept")
+
+	renderer, ok := DefaultRegistry.Negotiate(r, data)
+	if !ok {
+		renderError(w, r, http.StatusNotAcceptable, ErrorResponse{
+			Error: &ErrorInfo{
+				Code:    "NOT_ACCEPTABLE",
+				Message: "None of the requested media types can be produced",
+			},
+		})
+		return
+	}
+
+	write(w, renderer, statusCode, data)
+}
+
+// renderError writes error payloads, falling back to JSON rather than
+// replacing the original error with a 406. The request ID is included so
+// clients can quote it when reporting a problem. r may be nil when no request
+// is at hand, in which case JSON is written without a request ID.
+func renderError(w http.ResponseWriter, r *http.Request, statusCode int, data ErrorResponse) {
+	if data.Error != nil && data.Error.RequestID == "" && r != nil {
+		data.Error.RequestID = requestctx.RequestID(r.Context())
+	}
+
+	renderer, ok := DefaultRegistry.Negotiate(r, data)
+	if !ok {
+		renderer = JSONRenderer{Indent: isPrettyRequested(r)}
+	}
+	write(w, renderer, statusCode, data)
+}
+
+// write encodes into a buffer first so an encoding failure becomes a 500
+// instead of a truncated body behind an already sent status line
+func write(w http.ResponseWriter, renderer Renderer, statusCode int, data interface{}) {
+	buf := new(bytes.Buffer)
+	if err := renderer.Render(buf, data); err != nil {
+		buf.Reset()
+		_ = JSONRenderer{}.Render(buf, ErrorResponse{
+			Error: &ErrorInfo{
+				Code:    "RESPONSE_ENCODING_FAILED",
+				Message: "Failed to encode response",
+			},
+		})
+		renderer = JSONRenderer{}
+		statusCode = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", renderer.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
 	w.WriteHeader(statusCode)
-	_ = json.NewEncoder(w).Encode(data)
+	_, _ = w.Write(buf.Bytes())
 }
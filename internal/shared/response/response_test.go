@@ -0,0 +1,43 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-clean-template/internal/shared/requestctx"
+)
+
+func TestErrorRequestID(t *testing.T) {
+	withID := httptest.NewRequest(http.MethodGet, "/", nil)
+	withID = withID.WithContext(requestctx.WithRequestID(context.Background(), "host/abc-000001"))
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		want string
+	}{
+		{name: "request ID from the context", r: withID, want: `"request_id":"host/abc-000001"`},
+		{name: "no request ID", r: httptest.NewRequest(http.MethodGet, "/", nil), want: `"code":"BOOM"`},
+		{name: "nil request", r: nil, want: `"code":"BOOM"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Error(rec, tt.r, http.StatusInternalServerError, "BOOM", "Boom")
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status %d, want 500", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != (JSONRenderer{}).ContentType() {
+				t.Errorf("Content-Type = %q, want JSON", got)
+			}
+			if body := rec.Body.String(); !strings.Contains(body, tt.want) {
+				t.Errorf("body %s does not contain %s", body, tt.want)
+			}
+		})
+	}
+}
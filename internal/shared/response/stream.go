@@ -0,0 +1,329 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MediaTypeNDJSON      = "application/x-ndjson"
+	MediaTypeEventStream = "text/event-stream"
+
+	// DefaultStreamWriteTimeout bounds every individual write of a stream. It
+	// replaces the server wide WriteTimeout, which would otherwise cut off a
+	// long lived stream regardless of progress.
+	DefaultStreamWriteTimeout = 30 * time.Second
+
+	// DefaultHeartbeatInterval keeps idle SSE connections open through proxies
+	DefaultHeartbeatInterval = 15 * time.Second
+)
+
+// ErrStreamingUnsupported is returned when the response writer cannot flush
+var ErrStreamingUnsupported = errors.New("response writer does not support flushing")
+
+// StreamOptions configures NDJSON streams
+type StreamOptions struct {
+	// WriteTimeout is the deadline applied to each write; zero uses DefaultStreamWriteTimeout
+	WriteTimeout time.Duration
+}
+
+// stream owns a flushing response writer bound to the request lifetime
+type stream struct {
+	w            http.ResponseWriter
+	r            *http.Request
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+	mu           sync.Mutex
+}
+
+func newStream(w http.ResponseWriter, r *http.Request, contentType string, writeTimeout time.Duration) (*stream, error) {
+	if _, ok := w.(http.Flusher); !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	if writeTimeout <= 0 {
+		writeTimeout = DefaultStreamWriteTimeout
+	}
+
+	s := &stream{
+		w:            w,
+		r:            r,
+		rc:           http.NewResponseController(w),
+		writeTimeout: writeTimeout,
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	header.Del("Content-Length")
+
+	s.extendDeadline()
+	w.WriteHeader(http.StatusOK)
+	if err := s.flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// write sends p and flushes it, failing fast once the client has gone away
+func (s *stream) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.r.Context().Err(); err != nil {
+		return err
+	}
+
+	s.extendDeadline()
+	if _, err := s.w.Write(p); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *stream) flush() error {
+	s.w.(http.Flusher).Flush()
+	return s.r.Context().Err()
+}
+
+// extendDeadline pushes the connection write deadline forward. Writers that
+// do not support deadlines keep the server's WriteTimeout.
+func (s *stream) extendDeadline() {
+	_ = s.rc.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+}
+
+// NDJSON streams every value produced by seq as a newline delimited JSON
+// document. It stops early when the client disconnects or a write fails.
+func NDJSON[T any](w http.ResponseWriter, r *http.Request, seq iter.Seq[T], opts ...StreamOptions) error {
+	s, err := newStream(w, r, MediaTypeNDJSON, streamWriteTimeout(opts))
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	for item := range seq {
+		buf.Reset()
+		if err := json.NewEncoder(buf).Encode(item); err != nil {
+			return fmt.Errorf("failed to encode stream item: %w", err)
+		}
+		if err := s.write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NDJSONFromChannel streams values received from ch until it is closed or
+// the client disconnects
+func NDJSONFromChannel[T any](w http.ResponseWriter, r *http.Request, ch <-chan T, opts ...StreamOptions) error {
+	ctx := r.Context()
+	return NDJSON(w, r, func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-ch:
+				if !ok || !yield(item) {
+					return
+				}
+			}
+		}
+	}, opts...)
+}
+
+func streamWriteTimeout(opts []StreamOptions) time.Duration {
+	if len(opts) == 0 {
+		return 0
+	}
+	return opts[0].WriteTimeout
+}
+
+// SSEOptions configures a Server-Sent Events stream
+type SSEOptions struct {
+	// Retry is sent to the client as the reconnection delay; zero omits it
+	Retry time.Duration
+	// HeartbeatInterval sends comment frames while idle; zero uses
+	// DefaultHeartbeatInterval and a negative value disables heartbeats
+	HeartbeatInterval time.Duration
+	// WriteTimeout is the deadline applied to each write; zero uses DefaultStreamWriteTimeout
+	WriteTimeout time.Duration
+}
+
+// Event is a single Server-Sent Event. Data is written verbatim when it is a
+// string or []byte and JSON encoded otherwise.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// SSEWriter writes Server-Sent Events to a single client
+type SSEWriter struct {
+	stream      *stream
+	lastEventID string
+	ctx         context.Context
+	cancel      context.CancelFunc
+	// heartbeatDone is closed once the heartbeat goroutine has exited
+	heartbeatDone chan struct{}
+}
+
+// NewSSEWriter starts an event stream. The heartbeat writes to w from its own
+// goroutine, so handlers must defer Close right after a successful call:
+//
+//	sse, err := response.NewSSEWriter(w, r, response.SSEOptions{})
+//	if err != nil {
+//		return
+//	}
+//	defer sse.Close()
+//
+// Handlers should stop producing events once Done is closed.
+func NewSSEWriter(w http.ResponseWriter, r *http.Request, opts SSEOptions) (*SSEWriter, error) {
+	s, err := newStream(w, r, MediaTypeEventStream, opts.WriteTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	sse := &SSEWriter{
+		stream:        s,
+		lastEventID:   LastEventID(r),
+		ctx:           ctx,
+		cancel:        cancel,
+		heartbeatDone: make(chan struct{}),
+	}
+
+	if opts.Retry > 0 {
+		if err := s.write([]byte(formatRetry(opts.Retry) + "\n")); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	interval := opts.HeartbeatInterval
+	if interval == 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	if interval > 0 {
+		go sse.heartbeat(interval)
+	} else {
+		close(sse.heartbeatDone)
+	}
+
+	return sse, nil
+}
+
+// LastEventID returns the event ID a reconnecting client wants to resume
+// after, read from the Last-Event-ID header or the lastEventId query parameter
+func LastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// LastEventID returns the resume point sent by the client, if any
+func (s *SSEWriter) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done is closed when the client disconnects or the writer is closed
+func (s *SSEWriter) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes one event and flushes it to the client
+func (s *SSEWriter) Send(event Event) error {
+	frame, err := formatEvent(event)
+	if err != nil {
+		return err
+	}
+	if err := s.stream.write(frame); err != nil {
+		s.Close()
+		return err
+	}
+	return nil
+}
+
+// Close stops the heartbeat and waits for its goroutine to exit, so nothing
+// touches the ResponseWriter once the handler returns. It does not end the
+// HTTP response; returning from the handler does. Close is safe to call
+// more than once.
+func (s *SSEWriter) Close() {
+	s.cancel()
+	<-s.heartbeatDone
+}
+
+func (s *SSEWriter) heartbeat(interval time.Duration) {
+	defer close(s.heartbeatDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.stream.write([]byte(": heartbeat\n\n")); err != nil {
+				s.cancel()
+				return
+			}
+		}
+	}
+}
+
+func formatEvent(event Event) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if event.ID != "" {
+		buf.WriteString("id: " + sanitizeField(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		buf.WriteString("event: " + sanitizeField(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		buf.WriteString(formatRetry(event.Retry))
+	}
+
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event data: %w", err)
+		}
+		data = string(encoded)
+	}
+
+	// Multi-line payloads are split across data fields per the SSE spec
+	for _, line := range strings.Split(data, "\n") {
+		buf.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+func formatRetry(retry time.Duration) string {
+	return "retry: " + strconv.FormatInt(retry.Milliseconds(), 10) + "\n"
+}
+
+// sanitizeField strips line breaks that would let a value inject extra fields
+func sanitizeField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}